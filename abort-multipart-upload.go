@@ -24,7 +24,6 @@ import (
 	"net/http"
 	"net/url"
 
-	"github.com/minio/s3verify/signv4"
 )
 
 // newAbortMultipartUploadReq - Create a new HTTP request for an abort multipart API.
@@ -52,7 +51,7 @@ func newAbortMultipartUploadReq(config ServerConfig, bucketName, objectName, upl
 	abortMultipartUploadReq.URL = targetURL
 	abortMultipartUploadReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
 	abortMultipartUploadReq.Header.Set("User-Agent", appUserAgent)
-	abortMultipartUploadReq = signv4.SignV4(*abortMultipartUploadReq, config.Access, config.Secret, config.Region)
+	abortMultipartUploadReq = config.Sign(abortMultipartUploadReq)
 
 	return abortMultipartUploadReq, nil
 }
@@ -112,6 +111,9 @@ func verifyStatusAbortMultipartUpload(respStatusCode, expectedStatusCode int) er
 // mainAbortMultipartUpload - Entry point for the abort multipart upload API test.
 func mainAbortMultipartUpload(config ServerConfig, curTest int) bool {
 	message := fmt.Sprintf("[%02d/%d] Multipart (Abort Upload):", curTest, globalTotalNumTest)
+	if !currentProviderQuirks().Supports(CapabilityMultipartAbort) {
+		return skipMessage(message, "provider "+globalProvider+" does not support explicit multipart abort")
+	}
 	scanBar(message)
 	bucket := validBuckets[0]
 	validObject := multipartObjects[1] // This multipart has not been completed and will instead be aborted.
@@ -125,8 +127,10 @@ func mainAbortMultipartUpload(config ServerConfig, curTest int) bool {
 	}
 	// Spin scanBar
 	scanBar(message)
-	// Execute the request.
-	res, err := execRequest(req, config.Client, bucket.Name, validObject.Key)
+	// Execute the request, retrying transient failures: the uploadId this
+	// depends on can be several hours old (see the TODO above), so a blip
+	// here shouldn't be reported as a false negative.
+	res, err := execRequestWithRetry(req, config, bucket.Name, validObject.Key)
 	if err != nil {
 		printMessage(message, err)
 		return false
@@ -0,0 +1,515 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// lifecycleExpiration/lifecycleRule/lifecycleConfiguration model the subset
+// of BucketLifecycleConfiguration exercised by this test.
+type lifecycleExpiration struct {
+	Days int    `xml:"Days,omitempty"`
+	Date string `xml:"Date,omitempty"`
+}
+
+// lifecycleTransition models a single storage-class Transition within a rule.
+type lifecycleTransition struct {
+	Days         int    `xml:"Days,omitempty"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+// lifecycleNoncurrentVersionExpiration expires noncurrent object versions
+// NoncurrentDays after they became noncurrent.
+type lifecycleNoncurrentVersionExpiration struct {
+	NoncurrentDays int `xml:"NoncurrentDays"`
+}
+
+// lifecycleAbortIncompleteMultipartUpload aborts stalled multipart uploads
+// DaysAfterInitiation days after they were initiated.
+type lifecycleAbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}
+
+// lifecycleTag is a single key/value predicate used inside a rule's Filter,
+// either on its own or nested in an And block alongside Prefix.
+type lifecycleTag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// lifecycleAnd combines a Prefix with one or more Tags; required whenever a
+// rule's Filter needs more than a single predicate.
+type lifecycleAnd struct {
+	Prefix string         `xml:"Prefix,omitempty"`
+	Tags   []lifecycleTag `xml:"Tag,omitempty"`
+}
+
+// lifecycleFilter is a rule's Filter element, holding exactly one of Prefix,
+// Tag or And.
+type lifecycleFilter struct {
+	Prefix string        `xml:"Prefix,omitempty"`
+	Tag    *lifecycleTag `xml:"Tag,omitempty"`
+	And    *lifecycleAnd `xml:"And,omitempty"`
+}
+
+type lifecycleRule struct {
+	ID                             string                                   `xml:"ID"`
+	Prefix                         string                                   `xml:"Prefix,omitempty"`
+	Filter                         *lifecycleFilter                         `xml:"Filter,omitempty"`
+	Status                         string                                   `xml:"Status"`
+	Expiration                     *lifecycleExpiration                     `xml:"Expiration,omitempty"`
+	Transition                     *lifecycleTransition                     `xml:"Transition,omitempty"`
+	NoncurrentVersionExpiration    *lifecycleNoncurrentVersionExpiration    `xml:"NoncurrentVersionExpiration,omitempty"`
+	AbortIncompleteMultipartUpload *lifecycleAbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty"`
+}
+
+type lifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Rules   []lifecycleRule `xml:"Rule"`
+}
+
+// newPutBucketLifecycleReq - Create a new HTTP request for the PutBucketLifecycleConfiguration API.
+func newPutBucketLifecycleReq(config ServerConfig, bucketName string, lifecycle lifecycleConfiguration) (Request, error) {
+	var putBucketLifecycleReq = Request{
+		customHeader: http.Header{},
+	}
+
+	putBucketLifecycleReq.bucketName = bucketName
+	putBucketLifecycleReq.queryValues = url.Values{"lifecycle": []string{""}}
+
+	lifecycleBytes, err := xml.Marshal(lifecycle)
+	if err != nil {
+		return Request{}, err
+	}
+	reader := bytes.NewReader(lifecycleBytes)
+	md5Sum, sha256Sum, contentLength, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	reader.Seek(0, 0)
+	putBucketLifecycleReq.contentBody = reader
+	putBucketLifecycleReq.contentLength = contentLength
+	// S3 requires Content-MD5 on this subresource.
+	putBucketLifecycleReq.customHeader.Set("Content-MD5", hex.EncodeToString(md5Sum))
+	putBucketLifecycleReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	putBucketLifecycleReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return putBucketLifecycleReq, nil
+}
+
+// newGetBucketLifecycleReq - Create a new HTTP request for the GetBucketLifecycleConfiguration API.
+func newGetBucketLifecycleReq(config ServerConfig, bucketName string) (Request, error) {
+	var getBucketLifecycleReq = Request{
+		customHeader: http.Header{},
+	}
+
+	getBucketLifecycleReq.bucketName = bucketName
+	getBucketLifecycleReq.queryValues = url.Values{"lifecycle": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	getBucketLifecycleReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	getBucketLifecycleReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return getBucketLifecycleReq, nil
+}
+
+// verifyStatusBucketLifecycle - verify the status returned matches what is expected.
+func verifyStatusBucketLifecycle(respStatusCode, expectedStatusCode int) error {
+	if respStatusCode != expectedStatusCode {
+		return fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", expectedStatusCode, respStatusCode)
+	}
+	return nil
+}
+
+// getBucketLifecycleVerify - verify the round-tripped lifecycle configuration matches what was PUT.
+func getBucketLifecycleVerify(res *http.Response, expectedStatusCode int, expected lifecycleConfiguration) error {
+	if err := verifyStatusBucketLifecycle(res.StatusCode, expectedStatusCode); err != nil {
+		return err
+	}
+	if expectedStatusCode != http.StatusOK {
+		return nil
+	}
+	got := lifecycleConfiguration{}
+	if err := xmlDecoder(res.Body, &got); err != nil {
+		return err
+	}
+	if len(got.Rules) != len(expected.Rules) {
+		return fmt.Errorf("Unexpected Number Of Rules: wanted %v, got %v", len(expected.Rules), len(got.Rules))
+	}
+	for i, rule := range got.Rules {
+		wantExpiration := expected.Rules[i].Expiration
+		if rule.ID != expected.Rules[i].ID || rule.Status != expected.Rules[i].Status ||
+			rule.Prefix != expected.Rules[i].Prefix ||
+			(wantExpiration == nil) != (rule.Expiration == nil) ||
+			(wantExpiration != nil && rule.Expiration != nil && rule.Expiration.Days != wantExpiration.Days) {
+			return fmt.Errorf("Unexpected Rule: wanted %+v, got %+v", expected.Rules[i], rule)
+		}
+		wantTransition := expected.Rules[i].Transition
+		if (wantTransition == nil) != (rule.Transition == nil) ||
+			(wantTransition != nil && rule.Transition != nil &&
+				(rule.Transition.Days != wantTransition.Days || rule.Transition.StorageClass != wantTransition.StorageClass)) {
+			return fmt.Errorf("Unexpected Rule Transition: wanted %+v, got %+v", expected.Rules[i], rule)
+		}
+	}
+	return nil
+}
+
+// mainPutBucketLifecycle - Entry point for the Put/GetBucketLifecycleConfiguration API tests.
+func mainPutBucketLifecycle(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] BucketLifecycle (Put/Get):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	lifecycle := lifecycleConfiguration{
+		Rules: []lifecycleRule{
+			{
+				ID:         "s3verify-expire-rule",
+				Prefix:     "s3verify/",
+				Status:     "Enabled",
+				Expiration: &lifecycleExpiration{Days: 30},
+			},
+		},
+	}
+
+	putReq, err := newPutBucketLifecycleReq(config, bucketName, lifecycle)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := config.execRequest("PUT", putReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(putRes)
+	if err := verifyStatusBucketLifecycle(putRes.StatusCode, http.StatusOK); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	getReq, err := newGetBucketLifecycleReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRes, err := config.execRequest("GET", getReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(getRes)
+	if err := getBucketLifecycleVerify(getRes, http.StatusOK, lifecycle); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// newDeleteBucketLifecycleReq - Create a new HTTP request for the DeleteBucketLifecycle API.
+func newDeleteBucketLifecycleReq(config ServerConfig, bucketName string) (Request, error) {
+	var deleteBucketLifecycleReq = Request{
+		customHeader: http.Header{},
+	}
+
+	deleteBucketLifecycleReq.bucketName = bucketName
+	deleteBucketLifecycleReq.queryValues = url.Values{"lifecycle": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	deleteBucketLifecycleReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	deleteBucketLifecycleReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return deleteBucketLifecycleReq, nil
+}
+
+// mainPutBucketLifecycleAdvanced - Entry point for the rule-variant coverage
+// newer minio-go exercises: Transition with StorageClass,
+// NoncurrentVersionExpiration, AbortIncompleteMultipartUpload, and a Filter
+// combining Prefix with a Tag via And. Also proves DeleteBucketLifecycle
+// removes the configuration, leaving GetBucketLifecycle empty.
+func mainPutBucketLifecycleAdvanced(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] BucketLifecycle (Rule Variants):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	lifecycle := lifecycleConfiguration{
+		Rules: []lifecycleRule{
+			{
+				ID:     "s3verify-transition-rule",
+				Status: "Enabled",
+				Filter: &lifecycleFilter{
+					And: &lifecycleAnd{
+						Prefix: "s3verify/",
+						Tags:   []lifecycleTag{{Key: "s3verify", Value: "archive"}},
+					},
+				},
+				Transition:                     &lifecycleTransition{Days: 30, StorageClass: "GLACIER"},
+				NoncurrentVersionExpiration:    &lifecycleNoncurrentVersionExpiration{NoncurrentDays: 60},
+				AbortIncompleteMultipartUpload: &lifecycleAbortIncompleteMultipartUpload{DaysAfterInitiation: 7},
+			},
+		},
+	}
+
+	putReq, err := newPutBucketLifecycleReq(config, bucketName, lifecycle)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := config.execRequest("PUT", putReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRes)
+	if err := verifyStatusBucketLifecycle(putRes.StatusCode, http.StatusOK); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	deleteReq, err := newDeleteBucketLifecycleReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	deleteRes, err := config.execRequest("DELETE", deleteReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(deleteRes)
+	if err := verifyStatusBucketLifecycle(deleteRes.StatusCode, http.StatusNoContent); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	getReq, err := newGetBucketLifecycleReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRes, err := config.execRequest("GET", getReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(getRes)
+	if getRes.StatusCode != http.StatusNotFound {
+		printMessage(message, fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", http.StatusNotFound, getRes.StatusCode))
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainPutBucketLifecycleInvalid - Entry point for the malformed-rule
+// negative tests: a rule missing the required ID element, and a rule with
+// neither Expiration nor Transition, must both be rejected with
+// MalformedXML; a well-formed rule sent without the required Content-MD5
+// header must be rejected with InvalidRequest.
+func mainPutBucketLifecycleInvalid(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] BucketLifecycle (Invalid Rule):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	lifecycle := lifecycleConfiguration{
+		Rules: []lifecycleRule{
+			{
+				Prefix:     "s3verify-missing-id/",
+				Status:     "Enabled",
+				Expiration: &lifecycleExpiration{Days: 1},
+			},
+		},
+	}
+
+	putReq, err := newPutBucketLifecycleReq(config, bucketName, lifecycle)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := config.execRequest("PUT", putReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(putRes, http.StatusBadRequest, "MalformedXML")
+	closeResponse(putRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// A rule with neither Expiration nor Transition has no action and must
+	// be rejected the same way.
+	noActionLifecycle := lifecycleConfiguration{
+		Rules: []lifecycleRule{
+			{
+				ID:     "s3verify-no-action-rule",
+				Prefix: "s3verify-no-action/",
+				Status: "Enabled",
+			},
+		},
+	}
+	noActionReq, err := newPutBucketLifecycleReq(config, bucketName, noActionLifecycle)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	noActionRes, err := config.execRequest("PUT", noActionReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(noActionRes, http.StatusBadRequest, "MalformedXML")
+	closeResponse(noActionRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// An otherwise well-formed request sent without Content-MD5 must be
+	// rejected with InvalidRequest rather than silently accepted.
+	validLifecycle := lifecycleConfiguration{
+		Rules: []lifecycleRule{
+			{
+				ID:         "s3verify-missing-md5-rule",
+				Prefix:     "s3verify-missing-md5/",
+				Status:     "Enabled",
+				Expiration: &lifecycleExpiration{Days: 1},
+			},
+		},
+	}
+	noMD5Req, err := newPutBucketLifecycleReq(config, bucketName, validLifecycle)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	noMD5Req.customHeader.Del("Content-MD5")
+	noMD5Res, err := config.execRequest("PUT", noMD5Req)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(noMD5Res)
+	if err := verifyErrorCode(noMD5Res, http.StatusBadRequest, "InvalidRequest"); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainPutBucketLifecycleMultiRule - Entry point for a configuration combining
+// a pure Expiration rule scoped to a prefix with a pure Transition rule
+// targeting STANDARD_IA, verifying GetBucketLifecycleConfiguration round-trips
+// every field of both rules.
+func mainPutBucketLifecycleMultiRule(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] BucketLifecycle (Expiration + Transition):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	lifecycle := lifecycleConfiguration{
+		Rules: []lifecycleRule{
+			{
+				ID:         "s3verify-expire-prefix-rule",
+				Prefix:     "s3verify-expire/",
+				Status:     "Enabled",
+				Expiration: &lifecycleExpiration{Days: 45},
+			},
+			{
+				ID:         "s3verify-transition-ia-rule",
+				Prefix:     "s3verify-transition/",
+				Status:     "Enabled",
+				Transition: &lifecycleTransition{Days: 30, StorageClass: "STANDARD_IA"},
+			},
+		},
+	}
+
+	putReq, err := newPutBucketLifecycleReq(config, bucketName, lifecycle)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := config.execRequest("PUT", putReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRes)
+	if err := verifyStatusBucketLifecycle(putRes.StatusCode, http.StatusOK); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	getReq, err := newGetBucketLifecycleReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRes, err := config.execRequest("GET", getReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(getRes)
+	if err := getBucketLifecycleVerify(getRes, http.StatusOK, lifecycle); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	deleteReq, err := newDeleteBucketLifecycleReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	deleteRes, err := config.execRequest("DELETE", deleteReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(deleteRes)
+	if err := verifyStatusBucketLifecycle(deleteRes.StatusCode, http.StatusNoContent); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
@@ -0,0 +1,279 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// bucketLocationCache caches the result of GetBucketLocation per bucket so
+// repeated signed requests against the same bucket do not each pay for a
+// round trip just to discover the signing region.
+type bucketLocationCache struct {
+	sync.RWMutex
+	items map[string]string
+}
+
+// newBucketLocationCache - new, empty bucket location cache.
+func newBucketLocationCache() *bucketLocationCache {
+	return &bucketLocationCache{items: map[string]string{}}
+}
+
+func (c *bucketLocationCache) get(bucketName string) (string, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	region, ok := c.items[bucketName]
+	return region, ok
+}
+
+func (c *bucketLocationCache) set(bucketName, region string) {
+	c.Lock()
+	defer c.Unlock()
+	c.items[bucketName] = region
+}
+
+// newGetBucketLocationReq - Create a new HTTP request for the GetBucketLocation API.
+func newGetBucketLocationReq(config ServerConfig, bucketName string) (Request, error) {
+	var getBucketLocationReq = Request{
+		customHeader: http.Header{},
+	}
+
+	getBucketLocationReq.bucketName = bucketName
+	getBucketLocationReq.queryValues = url.Values{"location": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	getBucketLocationReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	getBucketLocationReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return getBucketLocationReq, nil
+}
+
+// getBucketLocation - issue GetBucketLocation for bucketName, consulting and
+// populating config's bucketLocationCache. An empty LocationConstraint means
+// "us-east-1" per the API's documented behavior.
+func getBucketLocation(config *ServerConfig, bucketName string) (string, error) {
+	if config.locationCache == nil {
+		config.locationCache = newBucketLocationCache()
+	}
+	if region, ok := config.locationCache.get(bucketName); ok {
+		return region, nil
+	}
+
+	req, err := newGetBucketLocationReq(*config, bucketName)
+	if err != nil {
+		return "", err
+	}
+	res, err := config.execRequest("GET", req)
+	if err != nil {
+		return "", err
+	}
+	defer closeResponse(res)
+
+	locationConstraint := createBucketConfiguration{}
+	if err := xmlDecoder(res.Body, &locationConstraint); err != nil {
+		return "", err
+	}
+	region := locationConstraint.Location
+	if region == "" {
+		region = globalDefaultRegion
+	}
+	config.locationCache.set(bucketName, region)
+	return region, nil
+}
+
+// verifyBucketLocation - verify the GetBucketLocation response is either the
+// configured region or empty (which is only valid for us-east-1).
+func verifyBucketLocation(res *http.Response, expectedRegion string) error {
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	locationConstraint := createBucketConfiguration{}
+	if err := xmlDecoder(res.Body, &locationConstraint); err != nil {
+		return err
+	}
+	got := locationConstraint.Location
+	if got == "" && expectedRegion == globalDefaultRegion {
+		return nil
+	}
+	if got != expectedRegion {
+		return fmt.Errorf("Unexpected LocationConstraint: wanted %v, got %v", expectedRegion, got)
+	}
+	return nil
+}
+
+// mainGetBucketLocation - Entry point for the GetBucketLocation API test.
+func mainGetBucketLocation(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] GetBucketLocation:", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+
+	req, err := newGetBucketLocationReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	res, err := config.execRequest("GET", req)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(res)
+	if err := verifyBucketLocation(res, config.Region); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// regionFromRedirect extracts the correct signing region from a response
+// that rejected a request signed for the wrong region, mirroring the remap
+// minio-go performs: both the 301 (PermanentRedirect) and 400
+// (AuthorizationHeaderMalformed) forms of this rejection carry the correct
+// region in the x-amz-bucket-region header. ok is false when res does not
+// indicate a region mismatch at all.
+func regionFromRedirect(res *http.Response) (region string, ok bool) {
+	if res.StatusCode != http.StatusMovedPermanently && res.StatusCode != http.StatusBadRequest {
+		return "", false
+	}
+	if region := res.Header.Get("x-amz-bucket-region"); region != "" {
+		return region, true
+	}
+	return "", false
+}
+
+// newGetBucketLocationReqForRegion builds a GetBucketLocation request signed
+// against region instead of config.Region, used to retry after a region
+// mismatch is detected.
+func newGetBucketLocationReqForRegion(config ServerConfig, bucketName, region string) (Request, error) {
+	retryConfig := config
+	retryConfig.Region = region
+	return newGetBucketLocationReq(retryConfig, bucketName)
+}
+
+// getBucketLocationWithRegionRetry issues GetBucketLocation against
+// config.Region and, if the response indicates the bucket actually lives in
+// a different region (a 301 or 400 carrying an x-amz-bucket-region header),
+// re-signs and retries exactly once against that region.
+func getBucketLocationWithRegionRetry(config ServerConfig, bucketName string) (*http.Response, error) {
+	req, err := newGetBucketLocationReq(config, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	res, err := config.execRequest("GET", req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusOK {
+		return res, nil
+	}
+	region, ok := regionFromRedirect(res)
+	closeResponse(res)
+	if !ok {
+		return nil, fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", http.StatusOK, res.StatusCode)
+	}
+	retryReq, err := newGetBucketLocationReqForRegion(config, bucketName, region)
+	if err != nil {
+		return nil, err
+	}
+	return config.execRequest("GET", retryReq)
+}
+
+// mainGetBucketLocationAutoRetry - Entry point for the region-auto-discovery
+// test: a GetBucketLocation request deliberately signed for the wrong
+// region must be transparently retried, via
+// getBucketLocationWithRegionRetry, against the region the server reports,
+// succeeding on the second attempt without the caller supplying the correct
+// region up front.
+func mainGetBucketLocationAutoRetry(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] GetBucketLocation (Auto Region Retry):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+
+	wrongRegionConfig := config
+	if wrongRegionConfig.Region == "us-west-2" {
+		wrongRegionConfig.Region = "eu-west-1"
+	} else {
+		wrongRegionConfig.Region = "us-west-2"
+	}
+
+	res, err := getBucketLocationWithRegionRetry(wrongRegionConfig, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(res)
+	if err := verifyBucketLocation(res, config.Region); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainGetBucketLocationWrongRegion - Entry point for the redirect test: a
+// request signed for the wrong region must be rejected with a 301 and an
+// x-amz-bucket-region header naming the correct one.
+func mainGetBucketLocationWrongRegion(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] GetBucketLocation (Wrong Region Redirect):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+
+	wrongRegionConfig := config
+	if wrongRegionConfig.Region == "us-west-2" {
+		wrongRegionConfig.Region = "eu-west-1"
+	} else {
+		wrongRegionConfig.Region = "us-west-2"
+	}
+
+	req, err := newGetBucketLocationReq(wrongRegionConfig, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	res, err := wrongRegionConfig.execRequest("GET", req)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(res)
+	if res.StatusCode != http.StatusMovedPermanently {
+		printMessage(message, fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", http.StatusMovedPermanently, res.StatusCode))
+		return false
+	}
+	if got := res.Header.Get("x-amz-bucket-region"); got != config.Region {
+		printMessage(message, fmt.Errorf("Unexpected x-amz-bucket-region: wanted %v, got %v", config.Region, got))
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
@@ -0,0 +1,670 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/pkg/set"
+)
+
+// cannedBucketPolicy - build the JSON policy document for one of the canned
+// BucketPolicy values, restricted to the given bucket and prefix.
+func cannedBucketPolicy(policy BucketPolicy, bucketName, prefix string) BucketAccessPolicy {
+	resource := "arn:aws:s3:::" + bucketName + "/" + prefix + "*"
+	statement := Statement{
+		Sid:    "s3verify-" + string(policy),
+		Effect: "Allow",
+		Principal: User{
+			AWS: set.CreateStringSet("*"),
+		},
+	}
+	switch policy {
+	case BucketPolicyReadOnly:
+		statement.Actions = []string{"s3:GetObject"}
+	case BucketPolicyWriteOnly:
+		statement.Actions = []string{"s3:PutObject"}
+	case BucketPolicyReadWrite:
+		statement.Actions = []string{"s3:GetObject", "s3:PutObject"}
+	}
+	statement.Resources = set.CreateStringSet(resource)
+	return BucketAccessPolicy{
+		Version:    "2012-10-17",
+		Statements: []Statement{statement},
+	}
+}
+
+// newPutBucketPolicyReq - Create a new HTTP request for the PutBucketPolicy API.
+func newPutBucketPolicyReq(config ServerConfig, bucketName string, policy BucketAccessPolicy) (Request, error) {
+	policyBytes, err := json.Marshal(policy)
+	if err != nil {
+		return Request{}, err
+	}
+	return newPutBucketPolicyRawReq(config, bucketName, policyBytes)
+}
+
+// newPutBucketPolicyRawReq - Create a new HTTP request for the
+// PutBucketPolicy API from a raw, possibly malformed, policy body. Used to
+// drive the invalid-input tests below without a BucketAccessPolicy forcing
+// well-formed JSON.
+func newPutBucketPolicyRawReq(config ServerConfig, bucketName string, policyBytes []byte) (Request, error) {
+	var putBucketPolicyReq = Request{
+		customHeader: http.Header{},
+	}
+
+	putBucketPolicyReq.bucketName = bucketName
+	putBucketPolicyReq.queryValues = url.Values{"policy": []string{""}}
+
+	reader := bytes.NewReader(policyBytes)
+	_, sha256Sum, contentLength, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	reader.Seek(0, 0)
+	putBucketPolicyReq.contentBody = reader
+	putBucketPolicyReq.contentLength = contentLength
+	putBucketPolicyReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	putBucketPolicyReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return putBucketPolicyReq, nil
+}
+
+// newGetBucketPolicyReq - Create a new HTTP request for the GetBucketPolicy API.
+func newGetBucketPolicyReq(config ServerConfig, bucketName string) (Request, error) {
+	var getBucketPolicyReq = Request{
+		customHeader: http.Header{},
+	}
+
+	getBucketPolicyReq.bucketName = bucketName
+	getBucketPolicyReq.queryValues = url.Values{"policy": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	getBucketPolicyReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	getBucketPolicyReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return getBucketPolicyReq, nil
+}
+
+// newDeleteBucketPolicyReq - Create a new HTTP request for the DeleteBucketPolicy API.
+func newDeleteBucketPolicyReq(config ServerConfig, bucketName string) (Request, error) {
+	var deleteBucketPolicyReq = Request{
+		customHeader: http.Header{},
+	}
+
+	deleteBucketPolicyReq.bucketName = bucketName
+	deleteBucketPolicyReq.queryValues = url.Values{"policy": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	deleteBucketPolicyReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	deleteBucketPolicyReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return deleteBucketPolicyReq, nil
+}
+
+// putBucketPolicyVerify - verify that the response returned matches what is expected.
+func putBucketPolicyVerify(res *http.Response, expectedStatusCode int, expectedError ErrorResponse) error {
+	if err := verifyStatusPutBucketPolicy(res.StatusCode, expectedStatusCode); err != nil {
+		return err
+	}
+	if err := verifyBodyPutBucketPolicy(res.Body, expectedError); err != nil {
+		return err
+	}
+	return nil
+}
+
+func verifyStatusPutBucketPolicy(respStatusCode, expectedStatusCode int) error {
+	if respStatusCode != expectedStatusCode {
+		return fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", expectedStatusCode, respStatusCode)
+	}
+	return nil
+}
+
+func verifyBodyPutBucketPolicy(resBody io.Reader, expectedError ErrorResponse) error {
+	if expectedError.Code == "" {
+		return nil
+	}
+	resError := ErrorResponse{}
+	if err := xmlDecoder(resBody, &resError); err != nil {
+		return err
+	}
+	if resError.Code != expectedError.Code {
+		return fmt.Errorf("Unexpected Error Code: wanted %v, got %v", expectedError.Code, resError.Code)
+	}
+	return nil
+}
+
+// stringSliceEqualUnordered reports whether a and b contain the same
+// strings, ignoring order and duplicates.
+func stringSliceEqualUnordered(a, b []string) bool {
+	return set.CreateStringSet(a...).Equals(set.CreateStringSet(b...))
+}
+
+// statementEquals reports whether two Statements are semantically
+// equivalent: same Effect, same Actions (as a set), and same Resources (as
+// a set). Sid is not compared since S3 may assign one even when the PUT
+// request left it blank.
+func statementEquals(a, b Statement) bool {
+	return a.Effect == b.Effect && stringSliceEqualUnordered(a.Actions, b.Actions) && a.Resources.Equals(b.Resources)
+}
+
+// getBucketPolicyVerify - verify the GetBucketPolicy response matches the
+// policy that was PUT. Statements are compared as a set: order does not
+// matter, only that every expected Statement has a matching counterpart.
+func getBucketPolicyVerify(res *http.Response, expectedStatusCode int, expectedPolicy BucketAccessPolicy) error {
+	if res.StatusCode != expectedStatusCode {
+		return fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", expectedStatusCode, res.StatusCode)
+	}
+	if expectedStatusCode != http.StatusOK {
+		return nil
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	gotPolicy := BucketAccessPolicy{}
+	if err := json.Unmarshal(body, &gotPolicy); err != nil {
+		return err
+	}
+	if len(gotPolicy.Statements) != len(expectedPolicy.Statements) {
+		return fmt.Errorf("Unexpected Number Of Statements: wanted %v, got %v", len(expectedPolicy.Statements), len(gotPolicy.Statements))
+	}
+	matched := make([]bool, len(gotPolicy.Statements))
+	for _, wantStmt := range expectedPolicy.Statements {
+		found := false
+		for i, gotStmt := range gotPolicy.Statements {
+			if matched[i] {
+				continue
+			}
+			if statementEquals(wantStmt, gotStmt) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("Unexpected Statements: no returned Statement matches Effect=%v Actions=%v Resources=%v", wantStmt.Effect, wantStmt.Actions, wantStmt.Resources)
+		}
+	}
+	return nil
+}
+
+// mainPutBucketPolicy - Entry point for the PutBucketPolicy API test.
+func mainPutBucketPolicy(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] BucketPolicy (Put/Get/Delete):", curTest, globalTotalNumTest)
+	if !currentProviderQuirks().Supports(CapabilityBucketPolicy) {
+		return skipMessage(message, "provider "+globalProvider+" does not support bucket policies")
+	}
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	policy := cannedBucketPolicy(BucketPolicyReadOnly, bucketName, "s3verify/")
+
+	// PUT the canned policy.
+	putReq, err := newPutBucketPolicyReq(config, bucketName, policy)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := config.execRequest("PUT", putReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(putRes)
+	if err := putBucketPolicyVerify(putRes, http.StatusNoContent, ErrorResponse{}); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// GET the policy back and make sure it round-trips.
+	getReq, err := newGetBucketPolicyReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRes, err := config.execRequest("GET", getReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(getRes)
+	if err := getBucketPolicyVerify(getRes, http.StatusOK, policy); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// DELETE the policy and confirm a subsequent GET reports no policy set.
+	delReq, err := newDeleteBucketPolicyReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	delRes, err := config.execRequest("DELETE", delReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(delRes)
+	if err := putBucketPolicyVerify(delRes, http.StatusNoContent, ErrorResponse{}); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	noPolicyReq, err := newGetBucketPolicyReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	noPolicyRes, err := config.execRequest("GET", noPolicyReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(noPolicyRes)
+	if noPolicyRes.StatusCode != http.StatusNotFound {
+		printMessage(message, fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", http.StatusNotFound, noPolicyRes.StatusCode))
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainPutBucketPolicyAnonymousGet - Entry point for the BucketPolicy
+// anonymous-access test: PUTs a canned ReadOnly policy scoped to a prefix,
+// then verifies an unsigned GET under that prefix succeeds and an unsigned
+// GET outside of it is rejected, exercising the policy's Resource match
+// independent of this tool's usual V4 signing path.
+func mainPutBucketPolicyAnonymousGet(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] BucketPolicy (Anonymous GetObject):", curTest, globalTotalNumTest)
+	if !currentProviderQuirks().Supports(CapabilityBucketPolicy) {
+		return skipMessage(message, "provider "+globalProvider+" does not support bucket policies")
+	}
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	prefix := "s3verify-anon/"
+	policy := cannedBucketPolicy(BucketPolicyReadOnly, bucketName, prefix)
+
+	putReq, err := newPutBucketPolicyReq(config, bucketName, policy)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := config.execRequest("PUT", putReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(putRes)
+	if err := putBucketPolicyVerify(putRes, http.StatusNoContent, ErrorResponse{}); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	allowedObject := s3verifyObjects[0]
+	allowedKey := prefix + allowedObject.Key
+	uploadReq, err := newPutObjectReq(config, bucketName, allowedKey, allowedObject.Body)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	uploadRes, err := config.execRequest("PUT", uploadReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(uploadRes)
+	if err := putObjectVerify(uploadRes, "200 OK"); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// An unsigned GET on an object under the allowed prefix must succeed.
+	allowedURL, err := makeTargetURL(config.Endpoint, bucketName, allowedKey, config.Region, nil)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	allowedRes, err := http.Get(allowedURL.String())
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(allowedRes)
+	if err := getObjectVerify(allowedRes, allowedObject.Body, http.StatusOK); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// An unsigned GET outside the allowed prefix must be rejected.
+	deniedObject := s3verifyObjects[1]
+	deniedURL, err := makeTargetURL(config.Endpoint, bucketName, deniedObject.Key, config.Region, nil)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	deniedRes, err := http.Get(deniedURL.String())
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(deniedRes)
+	if err := verifyErrorCode(deniedRes, http.StatusForbidden, "AccessDenied"); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainGetBucketPolicy - Entry point for the GetBucketPolicy API test, kept
+// independently invokable (via --only) from mainPutBucketPolicy. PUTs a
+// canned policy as setup, then verifies GET round-trips it.
+func mainGetBucketPolicy(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] GetBucketPolicy:", curTest, globalTotalNumTest)
+	if !currentProviderQuirks().Supports(CapabilityBucketPolicy) {
+		return skipMessage(message, "provider "+globalProvider+" does not support bucket policies")
+	}
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	policy := cannedBucketPolicy(BucketPolicyReadOnly, bucketName, "s3verify/")
+
+	putReq, err := newPutBucketPolicyReq(config, bucketName, policy)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := config.execRequest("PUT", putReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRes)
+	scanBar(message)
+
+	getReq, err := newGetBucketPolicyReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRes, err := config.execRequest("GET", getReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(getRes)
+	if err := getBucketPolicyVerify(getRes, http.StatusOK, policy); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainDeleteBucketPolicy - Entry point for the DeleteBucketPolicy API test,
+// kept independently invokable (via --only) from mainPutBucketPolicy. PUTs a
+// canned policy as setup, DELETEs it, then verifies a subsequent GET reports
+// no policy set.
+func mainDeleteBucketPolicy(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] DeleteBucketPolicy:", curTest, globalTotalNumTest)
+	if !currentProviderQuirks().Supports(CapabilityBucketPolicy) {
+		return skipMessage(message, "provider "+globalProvider+" does not support bucket policies")
+	}
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	policy := cannedBucketPolicy(BucketPolicyReadOnly, bucketName, "s3verify/")
+
+	putReq, err := newPutBucketPolicyReq(config, bucketName, policy)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := config.execRequest("PUT", putReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRes)
+	scanBar(message)
+
+	delReq, err := newDeleteBucketPolicyReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	delRes, err := config.execRequest("DELETE", delReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	if err := putBucketPolicyVerify(delRes, http.StatusNoContent, ErrorResponse{}); err != nil {
+		closeResponse(delRes)
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(delRes)
+	scanBar(message)
+
+	getReq, err := newGetBucketPolicyReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRes, err := config.execRequest("GET", getReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(getRes)
+	if getRes.StatusCode != http.StatusNotFound {
+		printMessage(message, fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", http.StatusNotFound, getRes.StatusCode))
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainDeleteBucketPolicyAnonymousGet - Entry point for the full PUT/anonymous
+// GetObject/DELETE/anonymous GetObject lifecycle: a canned read-only policy
+// grants an unsigned GET under a prefix, which must succeed; once the policy
+// is deleted, the identical unsigned GET must then be rejected.
+func mainDeleteBucketPolicyAnonymousGet(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] BucketPolicy (Delete Revokes Anonymous GetObject):", curTest, globalTotalNumTest)
+	if !currentProviderQuirks().Supports(CapabilityBucketPolicy) {
+		return skipMessage(message, "provider "+globalProvider+" does not support bucket policies")
+	}
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	prefix := "s3verify-anon-revoke/"
+	policy := cannedBucketPolicy(BucketPolicyReadOnly, bucketName, prefix)
+
+	putReq, err := newPutBucketPolicyReq(config, bucketName, policy)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := config.execRequest("PUT", putReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRes)
+	scanBar(message)
+
+	object := s3verifyObjects[0]
+	objectKey := prefix + object.Key
+	uploadReq, err := newPutObjectReq(config, bucketName, objectKey, object.Body)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	uploadRes, err := config.execRequest("PUT", uploadReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(uploadRes)
+	scanBar(message)
+
+	objectURL, err := makeTargetURL(config.Endpoint, bucketName, objectKey, config.Region, nil)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+
+	// While the policy is in effect, the unsigned GET must succeed.
+	allowedRes, err := http.Get(objectURL.String())
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = getObjectVerify(allowedRes, object.Body, http.StatusOK)
+	closeResponse(allowedRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	delReq, err := newDeleteBucketPolicyReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	delRes, err := config.execRequest("DELETE", delReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = putBucketPolicyVerify(delRes, http.StatusNoContent, ErrorResponse{})
+	closeResponse(delRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// Once the policy is gone, the identical unsigned GET must be rejected.
+	deniedRes, err := http.Get(objectURL.String())
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(deniedRes, http.StatusForbidden, "AccessDenied")
+	closeResponse(deniedRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// invalidBucketPolicy pairs a raw, invalid PutBucketPolicy body with the
+// error code S3 is expected to respond with.
+type invalidBucketPolicy struct {
+	Name string
+	Body string
+	Code string
+}
+
+// invalidBucketPolicies covers the documented ways a policy document can be
+// rejected: JSON that does not parse, an action S3 does not recognize, and a
+// resource ARN that does not match the bucket being configured.
+var invalidBucketPolicies = []invalidBucketPolicy{
+	{
+		Name: "malformed JSON",
+		Body: `{"Version": "2012-10-17", "Statement": [`,
+		Code: "MalformedPolicy",
+	},
+	{
+		Name: "unrecognized action",
+		Body: `{"Version":"2012-10-17","Statement":[{"Sid":"s3verify-invalid-action","Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:NotARealAction"],"Resource":["arn:aws:s3:::BUCKET_NAME/*"]}]}`,
+		Code: "InvalidPolicyDocument",
+	},
+	{
+		Name: "resource not matching the bucket",
+		Body: `{"Version":"2012-10-17","Statement":[{"Sid":"s3verify-mismatched-resource","Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::some-other-bucket/*"]}]}`,
+		Code: "MalformedPolicy",
+	},
+}
+
+// mainPutBucketPolicyInvalid - entry point for testing the PutBucketPolicy
+// API with invalid policy documents, mirroring the invalid-names pattern
+// already used by mainPutBucketInvalid.
+func mainPutBucketPolicyInvalid(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] BucketPolicy (Invalid Policies):", curTest, globalTotalNumTest)
+	if !currentProviderQuirks().Supports(CapabilityBucketPolicy) {
+		return skipMessage(message, "provider "+globalProvider+" does not support bucket policies")
+	}
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+
+	for _, invalid := range invalidBucketPolicies {
+		body := strings.Replace(invalid.Body, "BUCKET_NAME", bucketName, -1)
+		req, err := newPutBucketPolicyRawReq(config, bucketName, []byte(body))
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		res, err := config.execRequest("PUT", req)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		err = putBucketPolicyVerify(res, http.StatusBadRequest, ErrorResponse{Code: invalid.Code})
+		closeResponse(res)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		scanBar(message)
+	}
+
+	printMessage(message, nil)
+	return true
+}
@@ -25,16 +25,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
-
-	"github.com/minio/s3verify/signv4"
 )
 
-//
 func newCompleteMultipartUploadReq(config ServerConfig, bucketName, objectName, uploadID string, complete *completeMultipartUpload) (*http.Request, error) {
 	var completeMultipartUploadReq = &http.Request{
 		Header: map[string][]string{
-		// X-Amz-Content-Sha256 will be set dynamically,
-		// Content-Length will be set dynamically,
+			// X-Amz-Content-Sha256 will be set dynamically,
+			// Content-Length will be set dynamically,
 		},
 		// Body: will be set dynamically,
 		Method: "POST",
@@ -66,12 +63,10 @@ func newCompleteMultipartUploadReq(config ServerConfig, bucketName, objectName,
 	completeMultipartUploadReq.Header.Set("User-Agent", appUserAgent)
 	completeMultipartUploadReq.Body = ioutil.NopCloser(reader)
 
-	completeMultipartUploadReq = signv4.SignV4(*completeMultipartUploadReq, config.Access, config.Secret, config.Region)
+	completeMultipartUploadReq = config.Sign(completeMultipartUploadReq)
 	return completeMultipartUploadReq, nil
 }
 
-// TODO: So far only valid multipart requests are used. Implement tests that SHOULD fail.
-//
 // completeMultipartUploadVerify - verify tthat the response returned matches what is expected.
 func completeMultipartUploadVerify(res *http.Response, expectedStatusCode int) error {
 	if err := verifyStatusCompleteMultipartUpload(res.StatusCode, expectedStatusCode); err != nil {
@@ -136,13 +131,133 @@ func mainCompleteMultipartUpload(config ServerConfig, curTest int) bool {
 	defer closeResponse(res)
 	// Spin scanBar
 	scanBar(message)
-	// Verify the response.
+	// Buffer the body so it can be both verified and parsed for the
+	// resulting composite ETag: later tests (e.g. conditional HEAD against
+	// a multipart object) need object.ETag populated, which this test is
+	// the only place that can supply.
+	bodyBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
 	if err := completeMultipartUploadVerify(res, http.StatusOK); err != nil {
 		printMessage(message, err)
 		return false
 	}
+	var result completeMultipartUploadResult
+	if err := xmlDecoder(bytes.NewReader(bodyBytes), &result); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	object.ETag = trimQuotes(result.ETag)
 	// Spin scanBar
 	scanBar(message)
 	printMessage(message, nil)
 	return true
 }
+
+// mainCompleteMultipartUploadInvalid - Entry point for CompleteMultipartUpload
+// failure cases against its own, dedicated upload (not the shared
+// multipartObjects[0] fixture other tests complete): an empty Parts list
+// must be rejected with MalformedXML, and a part list naming the wrong
+// ETag for an otherwise-valid part number must be rejected with InvalidPart.
+func mainCompleteMultipartUploadInvalid(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] CompleteMultipartUpload (Invalid):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucket := validBuckets[0]
+	objectName := "s3verify-complete-multipart-invalid-object"
+
+	initiateReq, err := newInitiateMultipartUploadReq(config, bucket.Name, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	initiateRes, err := config.execRequest("POST", initiateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	uploadID, err := initiateMultipartUploadVerify(initiateRes, http.StatusOK)
+	closeResponse(initiateRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// An empty Parts list must be rejected.
+	emptyReq, err := newCompleteMultipartUploadReq(config, bucket.Name, objectName, uploadID, &completeMultipartUpload{})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	emptyRes, err := execRequest(emptyReq, config.Client, bucket.Name, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(emptyRes, http.StatusBadRequest, "MalformedXML")
+	closeResponse(emptyRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// Upload one real part, then Complete naming the wrong ETag for it.
+	partReq, err := newUploadPartReq(config, bucket.Name, objectName, uploadID, 1, []byte("s3verify-complete-multipart-invalid-payload"))
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	partRes, err := execRequest(partReq, config.Client, bucket.Name, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = uploadPartVerify(partRes, "200 OK")
+	closeResponse(partRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	wrongETag := "\"0123456789abcdef0123456789abcdef\""
+	wrongETagReq, err := newCompleteMultipartUploadReq(config, bucket.Name, objectName, uploadID, &completeMultipartUpload{
+		Parts: []completePart{{PartNumber: 1, ETag: wrongETag}},
+	})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	wrongETagRes, err := execRequest(wrongETagReq, config.Client, bucket.Name, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(wrongETagRes, http.StatusBadRequest, "InvalidPart")
+	closeResponse(wrongETagRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	abortReq, err := newAbortMultipartUploadReq(config, bucket.Name, objectName, uploadID)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	abortRes, err := execRequest(abortReq, config.Client, bucket.Name, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(abortRes)
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
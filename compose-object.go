@@ -0,0 +1,639 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// copyPartResult mirrors the CopyPartResult XML body UploadPartCopy returns
+// for each part.
+type copyPartResult struct {
+	XMLName      xml.Name `xml:"CopyPartResult"`
+	ETag         string   `xml:"ETag"`
+	LastModified string   `xml:"LastModified"`
+}
+
+// newUploadPartCopyReq - Create a new HTTP request for the UploadPartCopy
+// API: PUT ?partNumber=N&uploadId=... with an empty body and the copy
+// source (plus an optional byte range) set via headers.
+func newUploadPartCopyReq(config ServerConfig, destBucketName, destObjectName, uploadID string, partNumber int, sourceBucketName, sourceObjectName string, byteRange string) (Request, error) {
+	var uploadPartCopyReq = Request{
+		customHeader: http.Header{},
+	}
+
+	uploadPartCopyReq.bucketName = destBucketName
+	uploadPartCopyReq.objectName = destObjectName
+	uploadPartCopyReq.queryValues = url.Values{
+		"partNumber": []string{strconv.Itoa(partNumber)},
+		"uploadId":   []string{uploadID},
+	}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	uploadPartCopyReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	uploadPartCopyReq.customHeader.Set("x-amz-copy-source", url.QueryEscape(sourceBucketName+"/"+sourceObjectName))
+	if byteRange != "" {
+		uploadPartCopyReq.customHeader.Set("x-amz-copy-source-range", "bytes="+byteRange)
+	}
+	uploadPartCopyReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return uploadPartCopyReq, nil
+}
+
+// newUploadPartCopyIfMatchReq - Create a new HTTP request for UploadPartCopy
+// with the x-amz-copy-source-if-match conditional header set, so the copy is
+// only performed if the source's current ETag matches sourceETag.
+func newUploadPartCopyIfMatchReq(config ServerConfig, destBucketName, destObjectName, uploadID string, partNumber int, sourceBucketName, sourceObjectName, sourceETag string) (Request, error) {
+	req, err := newUploadPartCopyReq(config, destBucketName, destObjectName, uploadID, partNumber, sourceBucketName, sourceObjectName, "")
+	if err != nil {
+		return Request{}, err
+	}
+	req.customHeader.Set("x-amz-copy-source-if-match", sourceETag)
+	return req, nil
+}
+
+// newUploadPartCopyIfNoneMatchReq - Create a new HTTP request for
+// UploadPartCopy with the x-amz-copy-source-if-none-match conditional
+// header set, so the copy is only performed if the source's current ETag
+// does not match sourceETag.
+func newUploadPartCopyIfNoneMatchReq(config ServerConfig, destBucketName, destObjectName, uploadID string, partNumber int, sourceBucketName, sourceObjectName, sourceETag string) (Request, error) {
+	req, err := newUploadPartCopyReq(config, destBucketName, destObjectName, uploadID, partNumber, sourceBucketName, sourceObjectName, "")
+	if err != nil {
+		return Request{}, err
+	}
+	req.customHeader.Set("x-amz-copy-source-if-none-match", sourceETag)
+	return req, nil
+}
+
+// uploadPartCopyVerify - verify the response is a well formed CopyPartResult
+// with a non-empty ETag.
+func uploadPartCopyVerify(res *http.Response, expectedStatusCode int) (copyPartResult, error) {
+	result := copyPartResult{}
+	if res.StatusCode != expectedStatusCode {
+		return result, fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", expectedStatusCode, res.StatusCode)
+	}
+	if err := xmlDecoder(res.Body, &result); err != nil {
+		return result, err
+	}
+	if result.ETag == "" {
+		return result, fmt.Errorf("Unexpected CopyPartResult: missing ETag")
+	}
+	return result, nil
+}
+
+// composeSource describes one source object (or byte range of one) to be
+// assembled, via UploadPartCopy, into a single destination object.
+type composeSource struct {
+	BucketName string
+	ObjectName string
+	ByteRange  string // "" copies the whole object.
+}
+
+// composeSourceSize reports the byte size a composeSource contributes to the
+// destination object: the full source size (via HEAD), or the width of its
+// byte range.
+func composeSourceSize(config ServerConfig, source composeSource) (int64, error) {
+	if source.ByteRange != "" {
+		var start, end int64
+		if _, err := fmt.Sscanf(source.ByteRange, "%d-%d", &start, &end); err != nil {
+			return 0, fmt.Errorf("Unexpected ByteRange %q: %v", source.ByteRange, err)
+		}
+		return end - start + 1, nil
+	}
+	headReq, err := newHeadObjectReq(config, source.BucketName, source.ObjectName)
+	if err != nil {
+		return 0, err
+	}
+	headRes, err := config.execRequest("HEAD", headReq)
+	if err != nil {
+		return 0, err
+	}
+	defer closeResponse(headRes)
+	return headRes.ContentLength, nil
+}
+
+// mainComposeObject - Entry point for the multi-source UploadPartCopy
+// compose test: initiates a multipart upload on a destination key, issues
+// UploadPartCopy for each source (single-source full-object, single-source
+// range, and multi-source with >= 2 parts), completes the upload, then GETs
+// the destination and verifies the ETag uses the documented
+// md5-of-concatenated-part-md5s "-N" suffix form and the body byte-matches
+// the concatenation of the source slices, then HEADs the destination to
+// confirm its size equals the sum of the source ranges.
+func mainComposeObject(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ComposeObject (UploadPartCopy):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	destObjectName := "s3verify-compose-dest"
+
+	sources := []composeSource{
+		{BucketName: bucketName, ObjectName: s3verifyObjects[0].Key},
+		{BucketName: bucketName, ObjectName: s3verifyObjects[0].Key, ByteRange: "0-1023"},
+	}
+	wantBody := append([]byte{}, s3verifyObjects[0].Body...)
+	wantBody = append(wantBody, s3verifyObjects[0].Body[0:1024]...)
+	if len(s3verifyObjects) > 1 {
+		sources = append(sources, composeSource{BucketName: bucketName, ObjectName: s3verifyObjects[1].Key})
+		wantBody = append(wantBody, s3verifyObjects[1].Body...)
+	}
+
+	initiateReq, err := newInitiateMultipartUploadReq(config, bucketName, destObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	initiateRes, err := config.execRequest("POST", initiateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	uploadID, err := initiateMultipartUploadVerify(initiateRes, http.StatusOK)
+	closeResponse(initiateRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	var parts []completePart
+	for i, source := range sources {
+		partNumber := i + 1
+		partReq, err := newUploadPartCopyReq(config, bucketName, destObjectName, uploadID, partNumber, source.BucketName, source.ObjectName, source.ByteRange)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		partRes, err := config.execRequest("PUT", partReq)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		result, err := uploadPartCopyVerify(partRes, http.StatusOK)
+		closeResponse(partRes)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		parts = append(parts, completePart{PartNumber: partNumber, ETag: result.ETag})
+		scanBar(message)
+	}
+
+	completeReq, err := newCompleteMultipartUploadReq(config, bucketName, destObjectName, uploadID, &completeMultipartUpload{Parts: parts})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	completeRes, err := execRequest(completeReq, config.Client, bucketName, destObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = completeMultipartUploadVerify(completeRes, http.StatusOK)
+	closeResponse(completeRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	getReq, err := newGetObjectReq(config, bucketName, destObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRes, err := config.execRequest("GET", getReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(getRes)
+	// The multipart ETag (quotes optional) must end in "-N" for N parts; it is
+	// not a plain MD5 of the assembled body.
+	gotETag := getRes.Header.Get("ETag")
+	if !isMultipartETag(gotETag, len(parts)) {
+		printMessage(message, fmt.Errorf("Unexpected ETag: wanted multipart form ending in -%d, got %v", len(parts), gotETag))
+		return false
+	}
+	// The composed body must be the byte-exact concatenation of the sources,
+	// in order: the full first source, its first 1024 bytes again, then the
+	// full second source if one was available.
+	if err := getObjectVerify(getRes, wantBody, http.StatusOK); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	var wantSize int64
+	for _, source := range sources {
+		size, err := composeSourceSize(config, source)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		wantSize += size
+	}
+	headReq, err := newHeadObjectReq(config, bucketName, destObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	headRes, err := config.execRequest("HEAD", headReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(headRes)
+	if headRes.ContentLength != wantSize {
+		printMessage(message, fmt.Errorf("Unexpected Content-Length: wanted %v, got %v", wantSize, headRes.ContentLength))
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainComposeObjectInvalid - entry point for the UploadPartCopy failure
+// cases: a copy-source-range that runs past the end of the source object
+// must fail with InvalidRange, and completing a multipart upload with a
+// non-last part smaller than 5 MiB must fail with EntityTooSmall.
+func mainComposeObjectInvalid(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ComposeObject (Invalid Ranges/Sizes):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	sourceObjectName := s3verifyObjects[0].Key
+	destObjectName := "s3verify-compose-dest-invalid"
+
+	initiateReq, err := newInitiateMultipartUploadReq(config, bucketName, destObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	initiateRes, err := config.execRequest("POST", initiateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	uploadID, err := initiateMultipartUploadVerify(initiateRes, http.StatusOK)
+	closeResponse(initiateRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// An out-of-range copy-source-range must be rejected with InvalidRange.
+	badRangeReq, err := newUploadPartCopyReq(config, bucketName, destObjectName, uploadID, 1, bucketName, sourceObjectName, "0-999999999999")
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	badRangeRes, err := config.execRequest("PUT", badRangeReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(badRangeRes, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+	closeResponse(badRangeRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// A non-last part smaller than 5 MiB must be rejected at Complete time
+	// with EntityTooSmall.
+	part1Req, err := newUploadPartCopyReq(config, bucketName, destObjectName, uploadID, 1, bucketName, sourceObjectName, "0-1023")
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	part1Res, err := config.execRequest("PUT", part1Req)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	part1Result, err := uploadPartCopyVerify(part1Res, http.StatusOK)
+	closeResponse(part1Res)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	part2Req, err := newUploadPartCopyReq(config, bucketName, destObjectName, uploadID, 2, bucketName, sourceObjectName, "")
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	part2Res, err := config.execRequest("PUT", part2Req)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	part2Result, err := uploadPartCopyVerify(part2Res, http.StatusOK)
+	closeResponse(part2Res)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	parts := []completePart{
+		{PartNumber: 1, ETag: part1Result.ETag},
+		{PartNumber: 2, ETag: part2Result.ETag},
+	}
+	completeReq, err := newCompleteMultipartUploadReq(config, bucketName, destObjectName, uploadID, &completeMultipartUpload{Parts: parts})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	completeRes, err := execRequest(completeReq, config.Client, bucketName, destObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(completeRes, http.StatusBadRequest, "EntityTooSmall")
+	closeResponse(completeRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// verifyErrorCode - verify the response is expectedStatusCode and its XML
+// ErrorResponse.Code matches expectedCode.
+func verifyErrorCode(res *http.Response, expectedStatusCode int, expectedCode string) error {
+	if res.StatusCode != expectedStatusCode {
+		return fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", expectedStatusCode, res.StatusCode)
+	}
+	resError := ErrorResponse{}
+	if err := xmlDecoder(res.Body, &resError); err != nil {
+		return err
+	}
+	if resError.Code != expectedCode {
+		return fmt.Errorf("Unexpected Error Code: wanted %v, got %v", expectedCode, resError.Code)
+	}
+	return nil
+}
+
+// mainComposeObjectLargeRanges - entry point for UploadPartCopy at the scale
+// a real >5 GiB object requires: every part but the last must be at least
+// 5 GiB wide, unlike mainComposeObject's small byte ranges. This needs a
+// source object of at least 2*5GiB+1024 bytes, but the only object fixture
+// this suite ever provisions (s3verifyObjects[0], staged by prepareObjects)
+// is 60 bytes, so every UploadPartCopy range here would be rejected with
+// InvalidRange by a compliant server. Skip until there is infrastructure to
+// stage (and clean up) a real >10GiB source object for this test specifically.
+func mainComposeObjectLargeRanges(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ComposeObject (Large Ranges):", curTest, globalTotalNumTest)
+	return skipMessage(message, "no >10GiB source fixture is provisioned for large-range UploadPartCopy")
+}
+
+// isMultipartETag reports whether etag looks like the documented
+// "<32-hex-chars>-N" multipart ETag form for numParts parts.
+func isMultipartETag(etag string, numParts int) bool {
+	etag = trimQuotes(etag)
+	suffix := fmt.Sprintf("-%d", numParts)
+	if len(etag) != 32+len(suffix) || etag[32:] != suffix {
+		return false
+	}
+	_, err := hex.DecodeString(etag[:32])
+	return err == nil
+}
+
+// trimQuotes strips a pair of surrounding double quotes from an ETag header
+// value, if present.
+func trimQuotes(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// mainComposeObjectAbort - entry point for two more UploadPartCopy failure
+// cases: a x-amz-copy-source-if-match that does not match the source's
+// current ETag must be rejected with PreconditionFailed, and once a
+// multipart upload has been aborted, ListParts on its uploadID must report
+// NoSuchUpload rather than listing any parts.
+func mainComposeObjectAbort(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ComposeObject (If-Match/Abort):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	sourceObjectName := s3verifyObjects[0].Key
+	destObjectName := "s3verify-compose-dest-abort"
+
+	initiateReq, err := newInitiateMultipartUploadReq(config, bucketName, destObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	initiateRes, err := config.execRequest("POST", initiateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	uploadID, err := initiateMultipartUploadVerify(initiateRes, http.StatusOK)
+	closeResponse(initiateRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// A copy-source-if-match naming the wrong ETag must be rejected.
+	mismatchETag := "\"0123456789abcdef0123456789abcdef\""
+	badMatchReq, err := newUploadPartCopyIfMatchReq(config, bucketName, destObjectName, uploadID, 1, bucketName, sourceObjectName, mismatchETag)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	badMatchRes, err := config.execRequest("PUT", badMatchReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(badMatchRes, http.StatusPreconditionFailed, "PreconditionFailed")
+	closeResponse(badMatchRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// Upload one real part, then abort before completing.
+	partReq, err := newUploadPartCopyReq(config, bucketName, destObjectName, uploadID, 1, bucketName, sourceObjectName, "0-1023")
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	partRes, err := config.execRequest("PUT", partReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	_, err = uploadPartCopyVerify(partRes, http.StatusOK)
+	closeResponse(partRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	abortReq, err := newAbortMultipartUploadReq(config, bucketName, destObjectName, uploadID)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	abortRes, err := execRequest(abortReq, config.Client, bucketName, destObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = abortMultipartUploadVerify(abortRes, http.StatusNoContent, ErrorResponse{})
+	closeResponse(abortRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	listReq, err := newListPartsReq(config, bucketName, destObjectName, uploadID)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	listRes, err := execRequest(listReq, config.Client, bucketName, destObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(listRes, http.StatusNotFound, "NoSuchUpload")
+	closeResponse(listRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainComposeObjectIfNoneMatch - entry point for UploadPartCopy with
+// x-amz-copy-source-if-none-match: the copy must proceed when the supplied
+// ETag does not match the source's current ETag, and must be rejected with
+// PreconditionFailed when it does.
+func mainComposeObjectIfNoneMatch(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ComposeObject (If-None-Match):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	sourceObject := s3verifyObjects[0]
+	destObjectName := "s3verify-compose-dest-if-none-match"
+
+	initiateReq, err := newInitiateMultipartUploadReq(config, bucketName, destObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	initiateRes, err := config.execRequest("POST", initiateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	uploadID, err := initiateMultipartUploadVerify(initiateRes, http.StatusOK)
+	closeResponse(initiateRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// A copy-source-if-none-match naming an ETag that will never match must
+	// let the copy proceed normally.
+	mismatchETag := "\"0123456789abcdef0123456789abcdef\""
+	okReq, err := newUploadPartCopyIfNoneMatchReq(config, bucketName, destObjectName, uploadID, 1, bucketName, sourceObject.Key, mismatchETag)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	okRes, err := config.execRequest("PUT", okReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	_, err = uploadPartCopyVerify(okRes, http.StatusOK)
+	closeResponse(okRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// A copy-source-if-none-match naming the source's actual current ETag
+	// must be rejected.
+	badReq, err := newUploadPartCopyIfNoneMatchReq(config, bucketName, destObjectName, uploadID, 2, bucketName, sourceObject.Key, sourceObject.ETag)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	badRes, err := config.execRequest("PUT", badReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(badRes, http.StatusPreconditionFailed, "PreconditionFailed")
+	closeResponse(badRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	abortReq, err := newAbortMultipartUploadReq(config, bucketName, destObjectName, uploadID)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	abortRes, err := execRequest(abortReq, config.Client, bucketName, destObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = abortMultipartUploadVerify(abortRes, http.StatusNoContent, ErrorResponse{})
+	closeResponse(abortRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
@@ -24,7 +24,6 @@ import (
 	"net/http"
 	"net/url"
 
-	"github.com/minio/s3verify/signv4"
 )
 
 // newCopyObjectIfMatchReq - Create a new HTTP request for a PUT copy object.
@@ -55,7 +54,7 @@ func newCopyObjectIfMatchReq(config ServerConfig, sourceBucketName, sourceObject
 	copyObjectIfMatchReq.Header.Set("x-amz-copy-source", url.QueryEscape(sourceBucketName+"/"+sourceObjectName))
 	copyObjectIfMatchReq.Header.Set("x-amz-copy-source-if-match", ETag)
 
-	copyObjectIfMatchReq = signv4.SignV4(*copyObjectIfMatchReq, config.Access, config.Secret, config.Region)
+	copyObjectIfMatchReq = config.Sign(copyObjectIfMatchReq)
 	return copyObjectIfMatchReq, nil
 }
 
@@ -25,7 +25,6 @@ import (
 	"net/url"
 	"time"
 
-	"github.com/minio/s3verify/signv4"
 )
 
 // newCopyObjectIfModifiedSinceReq - Create a new HTTP request for CopyObject with the x-amz-copy-source-if-modified-since header set.
@@ -56,7 +55,7 @@ func newCopyObjectIfModifiedSinceReq(config ServerConfig, sourceBucketName, sour
 	copyObjectIfModifiedSinceReq.Header.Set("x-amz-copy-source-if-modified-since", lastModified.Format(http.TimeFormat))
 	copyObjectIfModifiedSinceReq.Header.Set("User-Agent", appUserAgent)
 
-	copyObjectIfModifiedSinceReq = signv4.SignV4(*copyObjectIfModifiedSinceReq, config.Access, config.Secret, config.Region)
+	copyObjectIfModifiedSinceReq = config.Sign(copyObjectIfModifiedSinceReq)
 	return copyObjectIfModifiedSinceReq, nil
 }
 
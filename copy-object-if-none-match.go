@@ -23,7 +23,6 @@ import (
 	"net/http"
 	"net/url"
 
-	"github.com/minio/s3verify/signv4"
 )
 
 // newPutObjectCopyIfNoneMatchReq - Create a new HTTP request for a CopyObject with the if-none-match header set.
@@ -52,7 +51,7 @@ func newCopyObjectIfNoneMatchReq(config ServerConfig, sourceBucketName, sourceOb
 	copyObjectIfNoneMatchReq.Header.Set("x-amz-copy-source", url.QueryEscape(sourceBucketName+"/"+sourceObjectName))
 	copyObjectIfNoneMatchReq.Header.Set("x-amz-copy-source-if-none-match", ETag)
 
-	copyObjectIfNoneMatchReq = signv4.SignV4(*copyObjectIfNoneMatchReq, config.Access, config.Secret, config.Region)
+	copyObjectIfNoneMatchReq = config.Sign(copyObjectIfNoneMatchReq)
 	return copyObjectIfNoneMatchReq, nil
 }
 
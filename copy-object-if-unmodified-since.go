@@ -25,7 +25,6 @@ import (
 	"net/url"
 	"time"
 
-	"github.com/minio/s3verify/signv4"
 )
 
 // newCopyObjectIfUnModifiedSinceReq - Create a new HTTP request for CopyObject with if-unmodified-since header set.
@@ -54,7 +53,7 @@ func newCopyObjectIfUnModifiedSinceReq(config ServerConfig, sourceBucketName, so
 	copyObjectIfUnModifiedSinceReq.Header.Set("x-amz-copy-source", url.QueryEscape(sourceBucketName+"/"+sourceObjectName))
 	copyObjectIfUnModifiedSinceReq.Header.Set("x-amz-copy-if-unmodified-since", lastModified.Format(http.TimeFormat))
 
-	copyObjectIfUnModifiedSinceReq = signv4.SignV4(*copyObjectIfUnModifiedSinceReq, config.Access, config.Secret, config.Region)
+	copyObjectIfUnModifiedSinceReq = config.Sign(copyObjectIfUnModifiedSinceReq)
 	return copyObjectIfUnModifiedSinceReq, nil
 }
 
@@ -0,0 +1,177 @@
+/*
+ * Minio S3verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// newCopyObjectMetadataDirectiveReq - Create a new HTTP request for PUT
+// object with the copy header set and an explicit x-amz-metadata-directive.
+// directive must be "COPY" (source metadata and Content-Type are preserved,
+// meta and contentType are ignored) or "REPLACE" (source metadata and
+// Content-Type are discarded in favor of meta and contentType). contentType
+// may be left empty under REPLACE to fall back to the default S3 assigns a
+// PUT Copy with no Content-Type of its own.
+func newCopyObjectMetadataDirectiveReq(config ServerConfig, sourceBucketName, sourceObjectName, destBucketName, destObjectName, directive, contentType string, meta map[string]string) (Request, error) {
+	var req = Request{
+		customHeader: http.Header{},
+	}
+
+	req.bucketName = destBucketName
+	req.objectName = destObjectName
+
+	// Body will be set by the server so don't upload any body here.
+	reader := bytes.NewReader([]byte(""))
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	req.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	req.customHeader.Set("x-amz-copy-source", url.QueryEscape(sourceBucketName+"/"+sourceObjectName))
+	req.customHeader.Set("x-amz-metadata-directive", directive)
+	for key, value := range meta {
+		req.customHeader.Set("x-amz-meta-"+key, value)
+	}
+	if directive == "REPLACE" && contentType != "" {
+		req.customHeader.Set("Content-Type", contentType)
+	}
+	req.customHeader.Set("User-Agent", appUserAgent)
+
+	return req, nil
+}
+
+// mainCopyObjectMetadataDirective - Entry point for the CopyObject
+// metadata-directive test. It copies a source object carrying a custom
+// x-amz-meta-s3verify header and a known Content-Type twice: once with
+// directive REPLACE and a new value/Content-Type, once with directive COPY,
+// then HEADs each destination to confirm REPLACE took the new values and
+// COPY preserved the source's.
+func mainCopyObjectMetadataDirective(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] CopyObject (Metadata-Directive):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	sourceObjectName := "s3verify-metadata-directive-source"
+	replaceDestObjectName := "s3verify-metadata-directive-replace-dest"
+	copyDestObjectName := "s3verify-metadata-directive-copy-dest"
+	sourceContentType := "text/x-s3verify-source"
+	replacedContentType := "text/x-s3verify-replaced"
+
+	putReq, err := newPutObjectReq(config, bucketName, sourceObjectName, []byte("s3verify-metadata-directive-payload"))
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putReq.Header.Set("x-amz-meta-s3verify", "source-value")
+	putReq.Header.Set("Content-Type", sourceContentType)
+	putRes, err := execRequest(putReq, config.Client, bucketName, sourceObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRes)
+	scanBar(message)
+
+	replaceReq, err := newCopyObjectMetadataDirectiveReq(config, bucketName, sourceObjectName, bucketName, replaceDestObjectName, "REPLACE", replacedContentType, map[string]string{"s3verify": "replaced-value"})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	replaceRes, err := config.execRequest("PUT", replaceReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = copyObjectVerify(replaceRes, http.StatusOK)
+	closeResponse(replaceRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	copyReq, err := newCopyObjectMetadataDirectiveReq(config, bucketName, sourceObjectName, bucketName, copyDestObjectName, "COPY", "", nil)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	copyRes, err := config.execRequest("PUT", copyReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = copyObjectVerify(copyRes, http.StatusOK)
+	closeResponse(copyRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	replaceHeadReq, err := newHeadObjectReq(config, bucketName, replaceDestObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	replaceHeadRes, err := config.execRequest("HEAD", replaceHeadReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	gotReplaced := replaceHeadRes.Header.Get("x-amz-meta-s3verify")
+	gotReplacedContentType := replaceHeadRes.Header.Get("Content-Type")
+	closeResponse(replaceHeadRes)
+	if gotReplaced != "replaced-value" {
+		printMessage(message, fmt.Errorf("Unexpected x-amz-meta-s3verify after REPLACE: wanted replaced-value, got %v", gotReplaced))
+		return false
+	}
+	if gotReplacedContentType != replacedContentType {
+		printMessage(message, fmt.Errorf("Unexpected Content-Type after REPLACE: wanted %v, got %v", replacedContentType, gotReplacedContentType))
+		return false
+	}
+	scanBar(message)
+
+	copyHeadReq, err := newHeadObjectReq(config, bucketName, copyDestObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	copyHeadRes, err := config.execRequest("HEAD", copyHeadReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	gotCopied := copyHeadRes.Header.Get("x-amz-meta-s3verify")
+	gotCopiedContentType := copyHeadRes.Header.Get("Content-Type")
+	closeResponse(copyHeadRes)
+	if gotCopied != "source-value" {
+		printMessage(message, fmt.Errorf("Unexpected x-amz-meta-s3verify after COPY: wanted source-value, got %v", gotCopied))
+		return false
+	}
+	if gotCopiedContentType != sourceContentType {
+		printMessage(message, fmt.Errorf("Unexpected Content-Type after COPY: wanted %v, got %v", sourceContentType, gotCopiedContentType))
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
@@ -73,7 +73,8 @@ func verifyHeaderCopyObject(header http.Header) error {
 	return nil
 }
 
-// verifyBodycopyObject - verify that the body returned is a valid CopyObject Result.
+// verifyBodycopyObject - verify that the body returned is a valid CopyObject
+// Result carrying a non-empty ETag and LastModified.
 func verifyBodyCopyObject(resBody io.Reader) error {
 	copyObjRes := copyObjectResult{}
 	decoder := xml.NewDecoder(resBody)
@@ -81,6 +82,12 @@ func verifyBodyCopyObject(resBody io.Reader) error {
 	if err != nil {
 		return err
 	}
+	if copyObjRes.ETag == "" {
+		return fmt.Errorf("Unexpected CopyObjectResult: missing ETag")
+	}
+	if copyObjRes.LastModified == "" {
+		return fmt.Errorf("Unexpected CopyObjectResult: missing LastModified")
+	}
 	return nil
 }
 
@@ -93,7 +100,9 @@ func verifyStatusCopyObject(respStatusCode, expectedStatusCode int) error {
 	return nil
 }
 
-// Test a PUT object request with the copy header set.
+// Test a PUT object request with the copy header set. Multi-source,
+// server-side copies assembled via UploadPartCopy (S3's ComposeObject-style
+// flow) are exercised separately in compose-object.go.
 func mainCopyObject(config ServerConfig, curTest int) bool {
 	message := fmt.Sprintf("[%02d/%d] CopyObject:", curTest, globalTotalNumTest)
 	// Spin scanBar
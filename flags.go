@@ -42,7 +42,17 @@ var globalFlags = []cli.Flag{
 	cli.StringFlag{
 		Name:   "url, u",
 		Usage:  "URL to S3 compatible server.",
-		EnvVar: "S3_URL",
+		EnvVar: "S3_URL,AWS_ENDPOINT_URL",
+	},
+	cli.StringFlag{
+		Name:   "profile",
+		Usage:  "Name of the profile to read from the AWS shared credentials file.",
+		EnvVar: "AWS_PROFILE",
+	},
+	cli.StringFlag{
+		Name:   "credentials-file",
+		Usage:  "Path to an AWS shared credentials file. Defaults to '~/.aws/credentials'.",
+		EnvVar: "AWS_SHARED_CREDENTIALS_FILE",
 	},
 	cli.BoolFlag{
 		Name:  "verbose, v",
@@ -60,4 +70,72 @@ var globalFlags = []cli.Flag{
 		Name:  "clean",
 		Usage: "Remove all s3verify objects and multipart object parts from the specified bucket. If only s3verify material is in the bucket the bucket itself will be removed as well.",
 	},
+	cli.StringFlag{
+		Name:  "report",
+		Usage: "Emit a structured test report. Supported formats: json, junit, markdown, tap. Optionally pair with a path, e.g. 'junit=path.xml', to override the default 's3verify-report.<format>' location.",
+	},
+	cli.StringFlag{
+		Name:  "only",
+		Usage: "Only run tests matching the given comma separated list of API names or tags, e.g. 'copyobject,multipart'.",
+	},
+	cli.StringFlag{
+		Name:  "skip",
+		Usage: "Skip tests matching the given comma separated list of API names or tags, e.g. 'presigned'.",
+	},
+	cli.StringFlag{
+		Name:  "suite",
+		Usage: "Run a named suite of tests, e.g. 'conditional'. Equivalent to --only=<suite>.",
+	},
+	cli.IntFlag{
+		Name:  "parallel",
+		Value: 1,
+		Usage: "Run up to N independent, non-critical tests concurrently. Critical tests always run serially as synchronization barriers.",
+	},
+	cli.IntFlag{
+		Name:  "prepare-concurrency",
+		Value: defaultPrepareConcurrency,
+		Usage: "Bound how many object/part uploads --prepare keeps in flight at once. Defaults to min(NumCPU*4, 64).",
+	},
+	cli.IntFlag{
+		Name:  "part-size",
+		Value: defaultPreparePartSize,
+		Usage: "Size in bytes of each part --prepare uploads while staging its multipart fixture. Defaults to 5MiB.",
+	},
+	cli.IntFlag{
+		Name:  "concurrency",
+		Value: 1,
+		Usage: "Bound how many per-object requests a test (e.g. GetObject) keeps in flight at once. Defaults to 1 (serial).",
+	},
+	cli.BoolFlag{
+		Name:  "continue-on-critical",
+		Usage: "Keep running remaining tests after a Critical test fails instead of stopping the run immediately.",
+	},
+	cli.BoolFlag{
+		Name:  "skip-notifications",
+		Usage: "Skip the ListenBucketNotification tests. Use against servers that do not implement Minio's streaming notification listener.",
+	},
+	cli.StringFlag{
+		Name:  "addressing",
+		Value: "path",
+		Usage: "URL addressing style to sign and send requests with: 'path', 'virtual' or 'auto' (runs every test once per style). Defaults to 'path'.",
+	},
+	cli.StringFlag{
+		Name:  "signature",
+		Value: "v4",
+		Usage: "Signature version to sign requests with. Supported: v2, v4.",
+	},
+	cli.IntFlag{
+		Name:  "max-retries",
+		Value: defaultMaxRetries,
+		Usage: "Maximum attempts execRequestWithRetry makes for a retryable failure (5xx, RequestTimeout, or a timed-out connection) before giving up. Defaults to 3.",
+	},
+	cli.StringFlag{
+		Name:  "provider",
+		Value: "aws",
+		Usage: "S3-compatible provider quirk profile: selects expected conditional HEAD/GET status codes and skips tests for capabilities the provider doesn't support, reporting them as SKIP instead of FAIL. Supported: aws, minio, gateway-200.",
+	},
+	cli.BoolFlag{
+		Name:  "object-lock",
+		Usage: "Enable the Object Lock / Retention / Legal Hold test suite. Not every S3-compatible endpoint supports Object Lock, so these tests are skipped unless this is set.",
+	},
 }
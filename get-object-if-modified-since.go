@@ -48,7 +48,7 @@ func newGetObjectIfModifiedSinceReq(config ServerConfig, bucketName, objectName
 
 	// Fill request URL and sign.
 	getObjectIfModifiedReq.URL = targetURL
-	getObjectIfModifiedReq = signv4.SignV4(*getObjectIfModifiedReq, config.Access, config.Secret, config.Region)
+	getObjectIfModifiedReq = config.Sign(getObjectIfModifiedReq)
 	return getObjectIfModifiedReq, nil
 }
 
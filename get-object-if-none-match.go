@@ -46,7 +46,7 @@ func newGetObjectIfNoneMatchReq(config ServerConfig, bucketName, objectName, ETa
 	getObjectIfNoneMatchReq.Header.Set("User-Agent", appUserAgent)
 	// Add the URL and sign
 	getObjectIfNoneMatchReq.URL = targetURL
-	getObjectIfNoneMatchReq = signv4.SignV4(*getObjectIfNoneMatchReq, config.Access, config.Secret, config.Region)
+	getObjectIfNoneMatchReq = config.Sign(getObjectIfNoneMatchReq)
 	return getObjectIfNoneMatchReq, nil
 }
 
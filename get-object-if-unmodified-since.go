@@ -110,7 +110,9 @@ func verifyHeaderGetObjectIfUnModifiedSince(header http.Header) error {
 	return nil
 }
 
-// Test the GET object API with the If-Unmodified-Since header set.
+// Test the GET object API with the If-Unmodified-Since header set. Requests
+// are fanned out across a worker pool bounded by globalRequestConcurrency
+// rather than one goroutine per object, which used to run unbounded.
 func mainGetObjectIfUnModifiedSince(config ServerConfig, curTest int) bool {
 	message := fmt.Sprintf("[%02d/%d] GetObject (If-Unmodified-Since):", curTest, globalTotalNumTest)
 	// Spin scanBar
@@ -121,68 +123,47 @@ func mainGetObjectIfUnModifiedSince(config ServerConfig, curTest int) bool {
 		printMessage(message, err)
 		return false
 	}
-	errCh := make(chan error, globalTotalNumTest)
 	bucket := validBuckets[0]
-	for _, object := range objects {
-		// Spin scanBar
-		scanBar(message)
-		go func(objectKey string, objectLastModified time.Time, objectBody []byte) {
-			// Form a request with a pastDate to make sure the object is not returned.
-			req, err := newGetObjectIfUnModifiedSinceReq(config, bucket.Name, objectKey, pastDate)
-			if err != nil {
-				errCh <- err
-				return
-			}
-			// Execute the request.
-			res, err := config.execRequest("GET", req)
-			if err != nil {
-				errCh <- err
-				return
-			}
-			defer closeResponse(res)
-			// Verify that the response returns an error.
-			if err := verifyGetObjectIfUnModifiedSince(res, []byte(""), http.StatusPreconditionFailed, true); err != nil {
-				errCh <- err
-				return
-			}
-			// Form a request with a date in the past.
-			goodReq, err := newGetObjectIfUnModifiedSinceReq(config, bucket.Name, objectKey, objectLastModified)
-			if err != nil {
-				errCh <- err
-				return
-			}
-			// Execute current request.
-			goodRes, err := config.execRequest("GET", goodReq)
-			if err != nil {
-				errCh <- err
-				return
-			}
-			defer closeResponse(goodRes)
-			// Verify that the lastModified date in a request returns the object.
-			if err := verifyGetObjectIfUnModifiedSince(goodRes, objectBody, http.StatusOK, false); err != nil {
-				errCh <- err
-				return
-			}
-			errCh <- nil
-		}(object.Key, object.LastModified, object.Body)
-		// Spin scanBar
-		scanBar(message)
-	}
-	count := len(objects)
-	for count > 0 {
-		count--
-		// Spin scanBar
-		scanBar(message)
-		err, ok := <-errCh
-		if !ok {
-			return false
+	errs, _ := runConcurrent(globalRequestConcurrency, len(objects), func(i int) (int64, error) {
+		object := objects[i]
+		// Form a request with a pastDate to make sure the object is not returned.
+		req, err := newGetObjectIfUnModifiedSinceReq(config, bucket.Name, object.Key, pastDate)
+		if err != nil {
+			return 0, err
+		}
+		// Execute the request.
+		res, err := config.execRequest("GET", req)
+		if err != nil {
+			return 0, err
 		}
+		defer closeResponse(res)
+		// Verify that the response returns an error.
+		if err := verifyGetObjectIfUnModifiedSince(res, []byte(""), http.StatusPreconditionFailed, true); err != nil {
+			return 0, err
+		}
+		// Form a request with a date in the past.
+		goodReq, err := newGetObjectIfUnModifiedSinceReq(config, bucket.Name, object.Key, object.LastModified)
+		if err != nil {
+			return 0, err
+		}
+		// Execute current request.
+		goodRes, err := config.execRequest("GET", goodReq)
+		if err != nil {
+			return 0, err
+		}
+		defer closeResponse(goodRes)
+		// Verify that the lastModified date in a request returns the object.
+		if err := verifyGetObjectIfUnModifiedSince(goodRes, object.Body, http.StatusOK, false); err != nil {
+			return 0, err
+		}
+		scanBar(message)
+		return 0, nil
+	})
+	for _, err := range errs {
 		if err != nil {
 			printMessage(message, err)
 			return false
 		}
-		// Spin scanBar
-		scanBar(message)
 	}
 	// Spin scanBar
 	scanBar(message)
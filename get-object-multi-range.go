@@ -0,0 +1,205 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpRange describes one inclusive byte range of a multi-range GET request.
+type httpRange struct {
+	Start int64
+	End   int64
+}
+
+// newGetObjectMultiRangeReq - Create a new GET object request carrying
+// multiple byte ranges in a single Range header (bytes=a-b,c-d,e-f).
+func newGetObjectMultiRangeReq(config ServerConfig, bucketName, objectName string, ranges []httpRange) (Request, error) {
+	var getObjectMultiRangeReq = Request{
+		customHeader: http.Header{},
+	}
+	getObjectMultiRangeReq.bucketName = bucketName
+	getObjectMultiRangeReq.objectName = objectName
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+
+	specs := make([]string, len(ranges))
+	for i, r := range ranges {
+		specs[i] = strconv.FormatInt(r.Start, 10) + "-" + strconv.FormatInt(r.End, 10)
+	}
+	getObjectMultiRangeReq.customHeader.Set("Range", "bytes="+strings.Join(specs, ","))
+	getObjectMultiRangeReq.customHeader.Set("User-Agent", appUserAgent)
+	getObjectMultiRangeReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	return getObjectMultiRangeReq, nil
+}
+
+// verifyBodyGetObjectMultiRange - verify a 206 multipart/byteranges
+// response: parses each MIME part's Content-Range: bytes X-Y/total header
+// and asserts the part body equals expectedBody[X:Y+1], in the same order
+// the ranges were requested.
+func verifyBodyGetObjectMultiRange(res *http.Response, ranges []httpRange, expectedBody []byte) error {
+	mediaType, params, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/byteranges") {
+		return fmt.Errorf("Unexpected Content-Type: wanted multipart/byteranges, got %v", mediaType)
+	}
+	boundary, ok := params["boundary"]
+	if !ok {
+		return fmt.Errorf("Missing multipart/byteranges boundary in Content-Type")
+	}
+	mr := multipart.NewReader(res.Body, boundary)
+	for i, wantRange := range ranges {
+		part, err := mr.NextPart()
+		if err != nil {
+			return fmt.Errorf("Missing part %v: %v", i, err)
+		}
+		var gotStart, gotEnd, gotTotal int64
+		if _, err := fmt.Sscanf(part.Header.Get("Content-Range"), "bytes %d-%d/%d", &gotStart, &gotEnd, &gotTotal); err != nil {
+			return fmt.Errorf("Unparsable Content-Range in part %v: %v", i, err)
+		}
+		if gotStart != wantRange.Start || gotEnd != wantRange.End {
+			return fmt.Errorf("Unexpected part %v Content-Range: wanted bytes %d-%d, got bytes %d-%d", i, wantRange.Start, wantRange.End, gotStart, gotEnd)
+		}
+		body, err := ioutil.ReadAll(part)
+		if err != nil {
+			return err
+		}
+		wantBody := expectedBody[wantRange.Start : wantRange.End+1]
+		if !bytes.Equal(body, wantBody) {
+			return fmt.Errorf("Unexpected part %v Body: wanted %v bytes, got %v bytes", i, len(wantBody), len(body))
+		}
+	}
+	if _, err := mr.NextPart(); err == nil {
+		return fmt.Errorf("Unexpected extra part in multipart/byteranges response")
+	}
+	return nil
+}
+
+// mainGetObjectMultiRange - Entry point for a multi-range GET: requests 3-5
+// random non-overlapping ranges of an object in a single request and
+// verifies the 206 multipart/byteranges response reassembles to exactly
+// those ranges, in order.
+func mainGetObjectMultiRange(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] GetObject (Multi-Range):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	rand.Seed(time.Now().UnixNano())
+	for _, object := range s3verifyObjects {
+		if object.Size < 8 {
+			// Too small to carve into several non-overlapping ranges.
+			continue
+		}
+		numRanges := 3 + rand.Intn(3) // 3-5 ranges.
+		segment := int64(object.Size) / int64(numRanges)
+		if segment < 2 {
+			continue
+		}
+		ranges := make([]httpRange, 0, numRanges)
+		for i := 0; i < numRanges; i++ {
+			segStart := int64(i) * segment
+			segEnd := segStart + segment - 1
+			if i == numRanges-1 {
+				segEnd = int64(object.Size) - 1
+			}
+			// Pick a random sub-range of this segment so ranges never
+			// overlap; neighbouring ranges can still be adjacent.
+			start := segStart + rand.Int63n(segment/2+1)
+			end := start + rand.Int63n(segEnd-start+1)
+			ranges = append(ranges, httpRange{Start: start, End: end})
+		}
+
+		req, err := newGetObjectMultiRangeReq(config, bucketName, object.Key, ranges)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		res, err := config.execRequest("GET", req)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		if err := verifyStatusGetObject(res.StatusCode, http.StatusPartialContent); err != nil {
+			closeResponse(res)
+			printMessage(message, err)
+			return false
+		}
+		err = verifyBodyGetObjectMultiRange(res, ranges, object.Body)
+		closeResponse(res)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		scanBar(message)
+	}
+	printMessage(message, nil)
+	return true
+}
+
+// mainGetObjectMultiRangeUnsatisfiable - verify a multi-range request whose
+// single range lies entirely past the end of the object is rejected with
+// 416 Requested Range Not Satisfiable and a Content-Range: bytes */size
+// header naming the object's actual size.
+func mainGetObjectMultiRangeUnsatisfiable(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] GetObject (Multi-Range Unsatisfiable):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	for _, object := range s3verifyObjects {
+		startRange := int64(object.Size) + 100
+		endRange := startRange + 100
+		req, err := newGetObjectMultiRangeReq(config, bucketName, object.Key, []httpRange{{Start: startRange, End: endRange}})
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		res, err := config.execRequest("GET", req)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		err = verifyErrorCode(res, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+		if err == nil {
+			wantContentRange := fmt.Sprintf("bytes */%d", object.Size)
+			if got := res.Header.Get("Content-Range"); got != wantContentRange {
+				err = fmt.Errorf("Unexpected Content-Range: wanted %v, got %v", wantContentRange, got)
+			}
+		}
+		closeResponse(res)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		scanBar(message)
+	}
+	printMessage(message, nil)
+	return true
+}
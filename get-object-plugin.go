@@ -0,0 +1,83 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/url"
+
+	"github.com/minio/s3verify/signv4"
+)
+
+// getObjectPlugin is the GetObject family's S3Verify implementation. It is
+// the first plugin migrated onto the S3Verify/FillHTTPRequest interfaces,
+// establishing the pattern other API tests (bucket notifications,
+// lifecycle, object tagging, ...) should follow as they move off the
+// hardcoded preparedTests/unpreparedTests slices and onto the registry.
+type getObjectPlugin struct {
+	bucketName, objectName string
+	region                 string
+	expectedBody           []byte
+}
+
+func (p *getObjectPlugin) MakeURLPath(endPointStr string) (*url.URL, error) {
+	return makeTargetURL(endPointStr, p.bucketName, p.objectName, p.region, nil)
+}
+
+func (p *getObjectPlugin) MakePlainRequest(endPointStr string) (*http.Request, error) {
+	targetURL, err := p.MakeURLPath(endPointStr)
+	if err != nil {
+		return nil, err
+	}
+	req := &http.Request{
+		Method: "GET",
+		Header: http.Header{},
+		URL:    targetURL,
+	}
+	req = p.SetHeaders(req)
+	req = p.SetBody(req)
+	return req, nil
+}
+
+func (p *getObjectPlugin) SetHeaders(req *http.Request) *http.Request {
+	// GET requests carry no body, so the payload hash is always of an empty slice.
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(signv4.Sum256([]byte{})))
+	req.Header.Set("User-Agent", appUserAgent)
+	return req
+}
+
+func (p *getObjectPlugin) SetBody(req *http.Request) *http.Request {
+	req.Body = nil // There is no body for GET requests.
+	return req
+}
+
+func (p *getObjectPlugin) SignRequest(req *http.Request, accessKeyID, secretAccessKey string) *http.Request {
+	return signv4.SignV4(*req, accessKeyID, secretAccessKey, p.region)
+}
+
+func (p *getObjectPlugin) ExecRequest(req *http.Request) (*http.Response, error) {
+	return http.DefaultClient.Do(req)
+}
+
+func (p *getObjectPlugin) VerifyResponse(res *http.Response) error {
+	return getObjectVerify(res, p.expectedBody, http.StatusOK)
+}
+
+func init() {
+	Register("GetObject", func() S3Verify { return &getObjectPlugin{} })
+}
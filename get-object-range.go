@@ -50,16 +50,13 @@ func newGetObjectRangeReq(config ServerConfig, bucketName, objectName string, st
 	return getObjectRangeReq, nil
 }
 
-// Test a GET object request with a range header set.
-func mainGetObjectRange(config ServerConfig, curTest int) bool {
+// testGetObjectRange - test a GET object request with a range header set.
+func testGetObjectRange(config ServerConfig, curTest int, bucketName string, testObjects []*ObjectInfo) bool {
 	message := fmt.Sprintf("[%02d/%d] GetObject (Range):", curTest, globalTotalNumTest)
 	// Spin scanBar
 	scanBar(message)
 	rand.Seed(time.Now().UnixNano())
-	// All getobject tests happen in s3verify created buckets
-	// on s3verify created objects.
-	bucketName := s3verifyBuckets[0].Name
-	for _, object := range s3verifyObjects {
+	for _, object := range testObjects {
 		// Spin scanBar
 		scanBar(message)
 		startRange := rand.Int63n(object.Size)
@@ -79,7 +76,12 @@ func mainGetObjectRange(config ServerConfig, curTest int) bool {
 		defer closeResponse(res)
 		bufRange := object.Body[startRange : endRange+1]
 		// Verify the response...these checks do not check the headers yet.
-		if err := getObjectVerify(res, bufRange, http.StatusPartialContent, nil); err != nil {
+		if err := getObjectVerify(res, bufRange, http.StatusPartialContent); err != nil {
+			printMessage(message, err)
+			return false
+		}
+		expectedContentRange := fmt.Sprintf("bytes %d-%d/%d", startRange, endRange, object.Size)
+		if err := verifyHeaderGetObjectRange(res.Header, expectedContentRange, endRange-startRange+1); err != nil {
 			printMessage(message, err)
 			return false
 		}
@@ -93,3 +95,15 @@ func mainGetObjectRange(config ServerConfig, curTest int) bool {
 	printMessage(message, nil)
 	return true
 }
+
+// mainGetObjectRangePrepared - Entry point for the GetObject range test if --prepare was used.
+func mainGetObjectRangePrepared(config ServerConfig, curTest int) bool {
+	bucketName := s3verifyBuckets[0].Name
+	return testGetObjectRange(config, curTest, bucketName, s3verifyObjects)
+}
+
+// mainGetObjectRangeUnPrepared - Entry point for the GetObject range test if --prepare was not used.
+func mainGetObjectRangeUnPrepared(config ServerConfig, curTest int) bool {
+	bucketName := unpreparedBuckets[0].Name
+	return testGetObjectRange(config, curTest, bucketName, objects)
+}
@@ -23,6 +23,8 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+
+	"github.com/minio/mc/pkg/console"
 )
 
 // newGetObjectReq - Create a new HTTP requests to perform.
@@ -96,38 +98,46 @@ func verifyStatusGetObject(respStatusCode, expectedStatusCode int) error {
 	return nil
 }
 
-// testGetObject - test a get object request.
+// testGetObject - test a get object request. GETs are fanned out across a
+// worker pool bounded by globalRequestConcurrency (1 == serial, the
+// historical behavior). When more than one worker is used, the aggregate
+// latency/throughput is printed after the usual pass/fail message.
 func testGetObject(config ServerConfig, curTest int, bucketName string, testObjects []*ObjectInfo) bool {
 	message := fmt.Sprintf("[%02d/%d] GetObject:", curTest, globalTotalNumTest)
-	// Use the bucket created in the mainPutBucketPrepared Test.
-	for _, object := range testObjects {
-		// Spin scanBar
-		scanBar(message)
+	scanBar(message)
+	errs, stats := runConcurrent(globalRequestConcurrency, len(testObjects), func(i int) (int64, error) {
+		object := testObjects[i]
 		// Create new GET object request.
 		req, err := newGetObjectReq(config, bucketName, object.Key)
 		if err != nil {
-			printMessage(message, err)
-			return false
+			return 0, err
 		}
 		// Execute the request.
 		res, err := config.execRequest("GET", req)
 		if err != nil {
-			printMessage(message, err)
-			return false
+			return 0, err
 		}
 		defer closeResponse(res)
 		// Verify the response.
 		if err := getObjectVerify(res, object.Body, http.StatusOK); err != nil {
+			return 0, err
+		}
+		scanBar(message)
+		return int64(object.Size), nil
+	})
+	for _, err := range errs {
+		if err != nil {
 			printMessage(message, err)
 			return false
 		}
-		// Spin scanBar
-		scanBar(message)
 	}
 	// Spin scanBar
 	scanBar(message)
 	// Test passed.
 	printMessage(message, nil)
+	if globalRequestConcurrency > 1 {
+		console.Println(stats)
+	}
 	return true
 }
 
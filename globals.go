@@ -17,19 +17,73 @@
 package main
 
 import (
+	"fmt"
 	"math/rand"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/s3verify/report"
 )
 
+// defaultPrepareConcurrency bounds how many PUT/UploadPart requests
+// prepareObjects/prepareMultipartParts keep in flight when the user does
+// not override it with --prepare-concurrency.
+var defaultPrepareConcurrency = minInt(runtime.NumCPU()*4, 64)
+
+// defaultPreparePartSize is the per-part payload size prepareMultipartParts
+// stages when the user does not override it with --part-size.
+const defaultPreparePartSize = 5 * 1024 * 1024 // 5MiB
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 var (
 	globalVerbose       = false
 	globalDefaultRegion = "us-east-1"
 	globalTotalNumTest  = 0
 	globalRandom        *rand.Rand
+	globalReportFormat  = "" // Empty disables structured reporting, preserving the scanBar-only behavior.
+	// globalReportPath overrides the default "s3verify-report.<format>"
+	// output location; set via "--report <format>=<path>".
+	globalReportPath = ""
+	globalReporter   *report.Reporter
+	// globalPrepareConcurrency bounds the worker pool used by prepareObjects
+	// and prepareMultipartParts. Set from --prepare-concurrency.
+	globalPrepareConcurrency = defaultPrepareConcurrency
+	// globalPreparePartSize is the per-part payload size prepareMultipartParts
+	// stages. Set from --part-size.
+	globalPreparePartSize = defaultPreparePartSize
+	// globalRequestConcurrency bounds the worker pool runConcurrent uses to
+	// fan a test's per-object requests out, e.g. testGetObject's GETs. 1
+	// (the default) preserves the historical serial behavior. Set from
+	// --concurrency.
+	globalRequestConcurrency = 1
+	// globalContinueOnCritical disables the default stop-the-run behavior
+	// when a Critical test fails. Set from --continue-on-critical.
+	globalContinueOnCritical = false
+	// globalAddressingStyle selects the URL style makeTargetURL builds:
+	// "path", "virtual" or "auto" (runAndRecord runs both and reports each
+	// separately). Set from --addressing.
+	globalAddressingStyle = "path"
+	// globalSkipNotifications skips the ListenBucketNotification tests when
+	// the target endpoint does not implement Minio's streaming listener.
+	// Set from --skip-notifications.
+	globalSkipNotifications = false
+	// globalProvider selects the providerQuirks profile conditional
+	// HEAD/GET tests assert against. Set from --provider.
+	globalProvider = "aws"
+	// globalObjectLock enables the Object Lock / Retention / Legal Hold test
+	// suite. Not every S3-compatible endpoint supports Object Lock, so these
+	// tests are skipped unless --object-lock is set.
+	globalObjectLock = false
 )
 
 // lockedRandSource provides protected rand source, implements rand.Source interface.
@@ -82,5 +136,56 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 	}
 	setGlobals(verbose, numTests)
 
+	globalReportFormat = ctx.String("report")
+	if format, path, ok := splitReportFlag(globalReportFormat); ok {
+		globalReportFormat = format
+		globalReportPath = path
+	}
+	if globalReportFormat != "" {
+		globalReporter = report.New(globalReportFormat)
+	}
+
+	if concurrency := ctx.Int("prepare-concurrency"); concurrency > 0 {
+		globalPrepareConcurrency = concurrency
+	}
+
+	if partSize := ctx.Int("part-size"); partSize > 0 {
+		globalPreparePartSize = partSize
+	}
+
+	if concurrency := ctx.Int("concurrency"); concurrency > 0 {
+		globalRequestConcurrency = concurrency
+	}
+
+	globalContinueOnCritical = ctx.Bool("continue-on-critical") || ctx.GlobalBool("continue-on-critical")
+
+	globalSkipNotifications = ctx.Bool("skip-notifications") || ctx.GlobalBool("skip-notifications")
+
+	globalObjectLock = ctx.Bool("object-lock") || ctx.GlobalBool("object-lock")
+
+	if provider := ctx.String("provider"); provider != "" {
+		globalProvider = provider
+	}
+
+	switch addressing := ctx.String("addressing"); addressing {
+	case "", "path", "virtual", "auto":
+		if addressing != "" {
+			globalAddressingStyle = addressing
+		}
+	default:
+		return fmt.Errorf("unsupported --addressing %q: only \"path\", \"virtual\" and \"auto\" are implemented", addressing)
+	}
+
 	return nil
 }
+
+// splitReportFlag parses the "--report" value, which is either a bare format
+// ("junit") or a "format=path" pair ("junit=path.xml") that overrides the
+// default "s3verify-report.<format>" output location.
+func splitReportFlag(value string) (format, path string, ok bool) {
+	idx := strings.Index(value, "=")
+	if idx < 0 {
+		return value, "", false
+	}
+	return value[:idx], value[idx+1:], true
+}
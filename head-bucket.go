@@ -24,7 +24,6 @@ import (
 	"io/ioutil"
 	"net/http"
 
-	"github.com/minio/s3verify/signv4"
 )
 
 // newHeadBucketReq - Create a new HTTP request for the HeadBucket API.
@@ -51,7 +50,7 @@ func newHeadBucketReq(config ServerConfig, bucketName string) (*http.Request, er
 	headBucketReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
 	headBucketReq.Header.Set("User-Agent", appUserAgent)
 
-	headBucketReq = signv4.SignV4(*headBucketReq, config.Access, config.Secret, config.Region)
+	headBucketReq = config.Sign(headBucketReq)
 	return headBucketReq, nil
 }
 
@@ -0,0 +1,151 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// newHeadObjectConditionalReq - Create a new HTTP request for HEAD object
+// with an arbitrary combination of the four RFC 7232 conditional headers
+// set, used to exercise the precedence rules between them. An empty value
+// for a given header leaves it unset.
+func newHeadObjectConditionalReq(config ServerConfig, bucketName, objectName, ifMatch, ifNoneMatch string, ifUnmodifiedSince, ifModifiedSince time.Time) (*http.Request, error) {
+	var headObjectConditionalReq = &http.Request{
+		Header: map[string][]string{
+			// X-Amz-Content-Sha256 will be set below.
+		},
+		Body:   nil, // No body is sent in HEAD object requests.
+		Method: "HEAD",
+	}
+
+	targetURL, err := makeTargetURL(config.Endpoint, bucketName, objectName, config.Region, nil)
+	if err != nil {
+		return nil, err
+	}
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return nil, err
+	}
+	headObjectConditionalReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	if ifMatch != "" {
+		headObjectConditionalReq.Header.Set("If-Match", ifMatch)
+	}
+	if ifNoneMatch != "" {
+		headObjectConditionalReq.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	if !ifUnmodifiedSince.IsZero() {
+		headObjectConditionalReq.Header.Set("If-Unmodified-Since", ifUnmodifiedSince.Format(http.TimeFormat))
+	}
+	if !ifModifiedSince.IsZero() {
+		headObjectConditionalReq.Header.Set("If-Modified-Since", ifModifiedSince.Format(http.TimeFormat))
+	}
+	headObjectConditionalReq.URL = targetURL
+	headObjectConditionalReq = config.Sign(headObjectConditionalReq)
+
+	return headObjectConditionalReq, nil
+}
+
+// verifyPreconditionResponse - verify a conditional HEAD/GET response
+// matches expectedStatusCode, has an empty body when the request was
+// rejected or short-circuited (304/412), and otherwise echoes the object's
+// current ETag.
+func verifyPreconditionResponse(res *http.Response, expectedStatusCode int, expectedETag string) error {
+	if res.StatusCode != expectedStatusCode {
+		return fmt.Errorf("Unexpected Status Received: wanted %v, got %v", expectedStatusCode, res.StatusCode)
+	}
+	if expectedStatusCode == http.StatusNotModified || expectedStatusCode == http.StatusPreconditionFailed {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		if len(body) != 0 {
+			return fmt.Errorf("Unexpected Body Recieved: %v responses must not return a body, but got back: %v", expectedStatusCode, string(body))
+		}
+		return nil
+	}
+	if gotETag := trimQuotes(res.Header.Get("ETag")); gotETag != expectedETag {
+		return fmt.Errorf("Unexpected ETag: wanted %v, got %v", expectedETag, gotETag)
+	}
+	return nil
+}
+
+// mainHeadObjectConditionalPrecedence - Entry point for the conditional HEAD
+// precedence test: RFC 7232 requires If-Match to be evaluated before, and
+// take precedence over, If-Unmodified-Since, and If-None-Match to be
+// evaluated before, and take precedence over, If-Modified-Since. A request
+// carrying both headers of a pair must follow the first header's outcome
+// even when the second header's condition, evaluated alone, would force
+// the opposite result.
+func mainHeadObjectConditionalPrecedence(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] HeadObject (Conditional Precedence):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucket := validBuckets[0]
+	object := objects[0]
+
+	// A matching If-Match must win even though If-Unmodified-Since, alone,
+	// would reject the request (LastModified in the future of the asserted
+	// unmodified-since time).
+	staleUnmodifiedSince := object.LastModified.Add(-time.Hour)
+	matchReq, err := newHeadObjectConditionalReq(config, bucket.Name, object.Key, object.ETag, "", staleUnmodifiedSince, time.Time{})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	matchRes, err := execRequest(matchReq, config.Client, bucket.Name, object.Key)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyPreconditionResponse(matchRes, http.StatusOK, object.ETag)
+	closeResponse(matchRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// A mismatched If-None-Match must win even though If-Modified-Since,
+	// alone, would report the object as unmodified (future timestamp).
+	futureModifiedSince := object.LastModified.Add(time.Hour)
+	noneMatchReq, err := newHeadObjectConditionalReq(config, bucket.Name, object.Key, "", "\"not-the-real-etag\"", time.Time{}, futureModifiedSince)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	noneMatchRes, err := execRequest(noneMatchReq, config.Client, bucket.Name, object.Key)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyPreconditionResponse(noneMatchRes, http.StatusOK, object.ETag)
+	closeResponse(noneMatchRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
@@ -143,8 +143,9 @@ func mainHeadObjectIfMatch(config ServerConfig, curTest int) bool {
 	defer closeResponse(badRes)
 	// Spin scanBar
 	scanBar(message)
-	// Verify the request sends back the right error.
-	if err := headObjectIfMatchVerify(badRes, http.StatusPreconditionFailed); err != nil {
+	// Verify the request sends back the right error. The expected status is
+	// provider-dependent: see providerQuirks.PreconditionFailedStatus.
+	if err := headObjectIfMatchVerify(badRes, currentProviderQuirks().PreconditionFailedStatus); err != nil {
 		printMessage(message, err)
 		return false
 	}
@@ -24,7 +24,6 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/minio/s3verify/signv4"
 )
 
 // newHeadObjectIfModifiedSinceReq - Create a new HTTP request for HEAD object with if-modified-since header set.
@@ -51,7 +50,7 @@ func newHeadObjectIfModifiedSinceReq(config ServerConfig, bucketName, objectName
 	headObjectIfModifiedSinceReq.URL = targetURL
 	headObjectIfModifiedSinceReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
 	headObjectIfModifiedSinceReq.Header.Set("If-Modified-Since", lastModified.Format(http.TimeFormat))
-	headObjectIfModifiedSinceReq = signv4.SignV4(*headObjectIfModifiedSinceReq, config.Access, config.Secret, config.Region)
+	headObjectIfModifiedSinceReq = config.Sign(headObjectIfModifiedSinceReq)
 
 	return headObjectIfModifiedSinceReq, nil
 }
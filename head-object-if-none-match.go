@@ -24,7 +24,6 @@ import (
 	"io/ioutil"
 	"net/http"
 
-	"github.com/minio/s3verify/signv4"
 )
 
 // newHeadObjectIfNoneMatch - Create a new HTTP request for HEAD object with if-none-match header set.
@@ -51,7 +50,7 @@ func newHeadObjectIfNoneMatchReq(config ServerConfig, bucketName, objectName, ET
 	headObjectIfNoneMatchReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
 	headObjectIfNoneMatchReq.Header.Set("User-Agent", appUserAgent)
 
-	headObjectIfNoneMatchReq = signv4.SignV4(*headObjectIfNoneMatchReq, config.Access, config.Secret, config.Region)
+	headObjectIfNoneMatchReq = config.Sign(headObjectIfNoneMatchReq)
 	return headObjectIfNoneMatchReq, nil
 }
 
@@ -149,8 +148,9 @@ func mainHeadObjectIfNoneMatch(config ServerConfig, curTest int) bool {
 	defer closeResponse(badRes)
 	// Spin scanBar
 	scanBar(message)
-	// Verify the response.
-	if err := headObjectIfNoneMatchVerify(badRes, 304); err != nil {
+	// Verify the response. The expected status is provider-dependent: see
+	// providerQuirks.NotModifiedStatus.
+	if err := headObjectIfNoneMatchVerify(badRes, currentProviderQuirks().NotModifiedStatus); err != nil {
 		printMessage(message, err)
 		return false
 	}
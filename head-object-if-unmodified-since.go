@@ -24,7 +24,6 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/minio/s3verify/signv4"
 )
 
 // newHeadObjectIfUnModifiedReq - Create a new HTTP request for HEAD object with if-unmodified-since header set.
@@ -51,7 +50,7 @@ func newHeadObjectIfUnModifiedSinceReq(config ServerConfig, bucketName, objectNa
 	headObjectIfUnModifiedSinceReq.Header.Set("If-Unmodified-Since", lastModified.Format(http.TimeFormat))
 	headObjectIfUnModifiedSinceReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
 	headObjectIfUnModifiedSinceReq.URL = targetURL
-	headObjectIfUnModifiedSinceReq = signv4.SignV4(*headObjectIfUnModifiedSinceReq, config.Access, config.Secret, config.Region)
+	headObjectIfUnModifiedSinceReq = config.Sign(headObjectIfUnModifiedSinceReq)
 
 	return headObjectIfUnModifiedSinceReq, nil
 }
@@ -64,7 +63,7 @@ func headObjectIfUnModifiedSinceVerify(res *http.Response, expectedStatus string
 	if err := verifyBodyHeadObjectIfUnModifiedSince(res); err != nil {
 		return err
 	}
-	if err := verifyHeaderHeadObjectIfUnModifiedSince(res); err != nil {
+	if err := verifyHeaderHeadObjectIfUnModifiedSince(res, "", "", ""); err != nil {
 		return err
 	}
 	return nil
@@ -92,11 +91,18 @@ func verifyBodyHeadObjectIfUnModifiedSince(res *http.Response) error {
 	return nil
 }
 
-// verifyHeaderHeadObjectIfUnModifiedSince - verify that the header returned matches what is expected.
-func verifyHeaderHeadObjectIfUnModifiedSince(res *http.Response) error {
+// verifyHeaderHeadObjectIfUnModifiedSince - verify that the header returned
+// matches what is expected. expectedLockMode/expectedRetainUntilDate/
+// expectedLegalHold assert the Object Lock headers via
+// verifyObjectLockHeaders; pass empty strings for an object that is not
+// locked.
+func verifyHeaderHeadObjectIfUnModifiedSince(res *http.Response, expectedLockMode, expectedRetainUntilDate, expectedLegalHold string) error {
 	if err := verifyStandardHeaders(res); err != nil {
 		return err
 	}
+	if err := verifyObjectLockHeaders(res.Header, expectedLockMode, expectedRetainUntilDate, expectedLegalHold); err != nil {
+		return err
+	}
 	return nil
 }
 
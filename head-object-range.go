@@ -0,0 +1,160 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// newHeadObjectRangeReq - Create a new HTTP request for HEAD object with a Range header set.
+func newHeadObjectRangeReq(config ServerConfig, bucketName, objectName string, startRange, endRange int64) (Request, error) {
+	// headObjectRangeReq - an HTTP request for HEAD with a range header set.
+	var headObjectRangeReq = Request{
+		customHeader: http.Header{},
+	}
+
+	// Set the bucketName and objectName.
+	headObjectRangeReq.bucketName = bucketName
+	headObjectRangeReq.objectName = objectName
+
+	reader := bytes.NewReader([]byte{}) // Compute hash using empty body because HEAD requests do not send a body.
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+
+	// Set the headers.
+	headObjectRangeReq.customHeader.Set("Range", "bytes="+strconv.FormatInt(startRange, 10)+"-"+strconv.FormatInt(endRange, 10))
+	headObjectRangeReq.customHeader.Set("User-Agent", appUserAgent)
+	headObjectRangeReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+
+	return headObjectRangeReq, nil
+}
+
+// headObjectRangeVerify - Verify that the response received matches what is expected.
+func headObjectRangeVerify(res *http.Response, expectedStatusCode int, startRange, endRange, objectSize int64) error {
+	if err := verifyStatusHeadObjectRange(res.StatusCode, expectedStatusCode); err != nil {
+		return err
+	}
+	if err := verifyBodyHeadObjectRange(res.Body); err != nil {
+		return err
+	}
+	if err := verifyHeaderHeadObjectRange(res.Header, startRange, endRange, objectSize); err != nil {
+		return err
+	}
+	return nil
+}
+
+// verifyStatusHeadObjectRange - Verify that the status received matches what is expected.
+func verifyStatusHeadObjectRange(respStatusCode, expectedStatusCode int) error {
+	if respStatusCode != expectedStatusCode {
+		err := fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", expectedStatusCode, respStatusCode)
+		return err
+	}
+	return nil
+}
+
+// verifyBodyHeadObjectRange - Verify that the body recieved is empty.
+func verifyBodyHeadObjectRange(resBody io.Reader) error {
+	body, err := ioutil.ReadAll(resBody)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(body, []byte{}) {
+		err := fmt.Errorf("Unexpected Body Recieved: HEAD requests should not return a body, but got back: %v", string(body))
+		return err
+	}
+	return nil
+}
+
+// verifyHeaderHeadObjectRange - Verify that the Content-Range and Accept-Ranges headers match what is expected.
+func verifyHeaderHeadObjectRange(header http.Header, startRange, endRange, objectSize int64) error {
+	if err := verifyStandardHeaders(header); err != nil {
+		return err
+	}
+	expectedContentRange := fmt.Sprintf("bytes %d-%d/%d", startRange, endRange, objectSize)
+	if gotContentRange := header.Get("Content-Range"); gotContentRange != expectedContentRange {
+		return fmt.Errorf("Unexpected Content-Range: wanted %v, got %v", expectedContentRange, gotContentRange)
+	}
+	if gotAcceptRanges := header.Get("Accept-Ranges"); gotAcceptRanges != "bytes" {
+		return fmt.Errorf("Unexpected Accept-Ranges: wanted %v, got %v", "bytes", gotAcceptRanges)
+	}
+	expectedContentLength := strconv.FormatInt(endRange-startRange+1, 10)
+	if gotContentLength := header.Get("Content-Length"); gotContentLength != expectedContentLength {
+		return fmt.Errorf("Unexpected Content-Length: wanted %v, got %v", expectedContentLength, gotContentLength)
+	}
+	return nil
+}
+
+// testHeadObjectRange - test the HeadObject API with a Range header set.
+func testHeadObjectRange(config ServerConfig, curTest int, bucketName string, testObjects []*ObjectInfo) bool {
+	message := fmt.Sprintf("[%02d/%d] HeadObject (Range):", curTest, globalTotalNumTest)
+	rand.Seed(time.Now().UnixNano())
+	for _, object := range testObjects {
+		// Spin scanBar
+		scanBar(message)
+		startRange := rand.Int63n(object.Size)
+		endRange := rand.Int63n(object.Size-startRange) + startRange
+		// Create a new HEAD object request with the range header set.
+		req, err := newHeadObjectRangeReq(config, bucketName, object.Key, startRange, endRange)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		// Spin scanBar
+		scanBar(message)
+		// Execute the request.
+		res, err := config.execRequest("HEAD", req)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		defer closeResponse(res)
+		// Verify the response.
+		if err := headObjectRangeVerify(res, http.StatusPartialContent, startRange, endRange, object.Size); err != nil {
+			printMessage(message, err)
+			return false
+		}
+		// Spin scanBar
+		scanBar(message)
+	}
+	// Test passed.
+	printMessage(message, nil)
+	return true
+}
+
+// mainHeadObjectRangePrepared - entry point for HeadObject (Range) test with --prepare used.
+func mainHeadObjectRangePrepared(config ServerConfig, curTest int) bool {
+	// Run on s3verify created buckets.
+	bucketName := s3verifyBuckets[0].Name
+	return testHeadObjectRange(config, curTest, bucketName, s3verifyObjects)
+}
+
+// mainHeadObjectRangeUnPrepared - entry point for HeadObject (Range) test without --prepare used.
+func mainHeadObjectRangeUnPrepared(config ServerConfig, curTest int) bool {
+	// Needs to only run on s3verify created objects.
+	bucketName := unpreparedBuckets[0].Name
+	return testHeadObjectRange(config, curTest, bucketName, objects)
+}
@@ -48,7 +48,7 @@ func newListBucketsReq(config ServerConfig) (*http.Request, error) {
 	listBucketsReq.URL = targetURL
 	listBucketsReq.Header.Set("User-Agent", appUserAgent)
 	// Sign the necessary headers.
-	listBucketsReq = signv4.SignV4(*listBucketsReq, config.Access, config.Secret, config.Region)
+	listBucketsReq = config.Sign(listBucketsReq)
 	return listBucketsReq, nil
 }
 
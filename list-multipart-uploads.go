@@ -0,0 +1,197 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// newListMultipartUploadsReq - Create a new HTTP request for the
+// ListMultipartUploads API (GET /<bucket>?uploads).
+func newListMultipartUploadsReq(config ServerConfig, bucketName string) (*http.Request, error) {
+	// listMultipartUploadsReq - a new HTTP request for ListMultipartUploads.
+	var listMultipartUploadsReq = &http.Request{
+		Header: map[string][]string{
+			// X-Amz-Content-Sha256 will be set below.
+		},
+		Body:   nil, // There is no body sent for GET requests.
+		Method: "GET",
+	}
+	urlValues := make(url.Values)
+	urlValues.Set("uploads", "")
+
+	targetURL, err := makeTargetURL(config.Endpoint, bucketName, "", config.Region, urlValues)
+	if err != nil {
+		return nil, err
+	}
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return nil, err
+	}
+	listMultipartUploadsReq.URL = targetURL
+	listMultipartUploadsReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	listMultipartUploadsReq.Header.Set("User-Agent", appUserAgent)
+	listMultipartUploadsReq = config.Sign(listMultipartUploadsReq)
+	return listMultipartUploadsReq, nil
+}
+
+// listMultipartUploadsVerify - verify the response returned matches what is
+// expected, and hand back the decoded ListMultipartUploadsResult so the
+// caller can inspect which uploads are currently listed.
+func listMultipartUploadsVerify(res *http.Response, expectedStatusCode int) (listMultipartUploadsResult, error) {
+	result := listMultipartUploadsResult{}
+	if err := verifyStatusListMultipartUploads(res.StatusCode, expectedStatusCode); err != nil {
+		return result, err
+	}
+	if err := xmlDecoder(res.Body, &result); err != nil {
+		return result, err
+	}
+	if err := verifyHeaderListMultipartUploads(res.Header); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// verifyStatusListMultipartUploads - verify the status returned matches what is expected.
+func verifyStatusListMultipartUploads(respStatusCode, expectedStatusCode int) error {
+	if respStatusCode != expectedStatusCode {
+		return fmt.Errorf("Unexpected Status Received: wanted %v, got %v", expectedStatusCode, respStatusCode)
+	}
+	return nil
+}
+
+// verifyHeaderListMultipartUploads - verify the header returned matches what is expected.
+func verifyHeaderListMultipartUploads(header http.Header) error {
+	return verifyStandardHeaders(header)
+}
+
+// uploadIsListed reports whether uploadID for objectKey appears among the
+// in-progress uploads a ListMultipartUploads call returned.
+func uploadIsListed(result listMultipartUploadsResult, objectKey, uploadID string) bool {
+	for _, upload := range result.Uploads {
+		if upload.Key == objectKey && upload.UploadID == uploadID {
+			return true
+		}
+	}
+	return false
+}
+
+// mainAbortMultipartUploadCleanup - Entry point verifying that the server
+// actually forgets an upload after AbortMultipartUpload, rather than just
+// returning 204 without freeing it: ListMultipartUploads must list the
+// upload beforehand and must no longer list it afterward. Runs against its
+// own dedicated upload, distinct from the multipartObjects[1] fixture
+// mainAbortMultipartUpload consumes.
+func mainAbortMultipartUploadCleanup(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] Multipart (Abort Upload Cleanup):", curTest, globalTotalNumTest)
+	if !currentProviderQuirks().Supports(CapabilityMultipartAbort) {
+		return skipMessage(message, "provider "+globalProvider+" does not support explicit multipart abort")
+	}
+	scanBar(message)
+	bucket := validBuckets[0]
+	objectName := "s3verify-abort-cleanup-object"
+
+	initiateReq, err := newInitiateMultipartUploadReq(config, bucket.Name, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	initiateRes, err := config.execRequest("POST", initiateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	uploadID, err := initiateMultipartUploadVerify(initiateRes, http.StatusOK)
+	closeResponse(initiateRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// Before abort: ListMultipartUploads must list this uploadID.
+	beforeReq, err := newListMultipartUploadsReq(config, bucket.Name)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	beforeRes, err := execRequest(beforeReq, config.Client, bucket.Name, "")
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	beforeList, err := listMultipartUploadsVerify(beforeRes, http.StatusOK)
+	closeResponse(beforeRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	if !uploadIsListed(beforeList, objectName, uploadID) {
+		printMessage(message, fmt.Errorf("ListMultipartUploads did not list upload %v for %v before abort", uploadID, objectName))
+		return false
+	}
+	scanBar(message)
+
+	abortReq, err := newAbortMultipartUploadReq(config, bucket.Name, objectName, uploadID)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	abortRes, err := execRequestWithRetry(abortReq, config, bucket.Name, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = abortMultipartUploadVerify(abortRes, 204, ErrorResponse{})
+	closeResponse(abortRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// After abort: ListMultipartUploads must no longer list this uploadID.
+	afterReq, err := newListMultipartUploadsReq(config, bucket.Name)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	afterRes, err := execRequest(afterReq, config.Client, bucket.Name, "")
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	afterList, err := listMultipartUploadsVerify(afterRes, http.StatusOK)
+	closeResponse(afterRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	if uploadIsListed(afterList, objectName, uploadID) {
+		printMessage(message, fmt.Errorf("ListMultipartUploads still lists upload %v for %v after abort", uploadID, objectName))
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
@@ -23,10 +23,30 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 )
 
+// listObjectsV2Params bundles the ListObjects V2 parameters beyond
+// list-type=2 that newListObjectsV2ReqParams can attach: pagination
+// (MaxKeys/ContinuationToken/StartAfter), owner reporting, and URL encoding
+// of returned keys.
+type listObjectsV2Params struct {
+	MaxKeys           int
+	ContinuationToken string
+	StartAfter        string
+	FetchOwner        bool
+	EncodingType      string // "" or "url".
+}
+
 // newListObjectsV2Req - Create a new HTTP request for ListObjects V2 API.
 func newListObjectsV2Req(config ServerConfig, bucketName string) (Request, error) {
+	return newListObjectsV2ReqParams(config, bucketName, listObjectsV2Params{})
+}
+
+// newListObjectsV2ReqParams - Create a new HTTP request for the ListObjects
+// V2 API with pagination/start-after/fetch-owner/encoding-type set.
+func newListObjectsV2ReqParams(config ServerConfig, bucketName string, params listObjectsV2Params) (Request, error) {
 	// listObjectsV2Req - a new HTTP request for ListObjects V2 API.
 	var listObjectsV2Req = Request{
 		customHeader: http.Header{},
@@ -38,6 +58,21 @@ func newListObjectsV2Req(config ServerConfig, bucketName string) (Request, error
 	// Set URL query values.
 	urlValues := make(url.Values)
 	urlValues.Set("list-type", "2")
+	if params.MaxKeys > 0 {
+		urlValues.Set("max-keys", strconv.Itoa(params.MaxKeys))
+	}
+	if params.ContinuationToken != "" {
+		urlValues.Set("continuation-token", params.ContinuationToken)
+	}
+	if params.StartAfter != "" {
+		urlValues.Set("start-after", params.StartAfter)
+	}
+	if params.FetchOwner {
+		urlValues.Set("fetch-owner", "true")
+	}
+	if params.EncodingType != "" {
+		urlValues.Set("encoding-type", params.EncodingType)
+	}
 	listObjectsV2Req.queryValues = urlValues
 
 	// No body is sent with GET requests.
@@ -142,3 +177,188 @@ func mainListObjectsV2(config ServerConfig, curTest int) bool {
 	printMessage(message, nil)
 	return true
 }
+
+// mainListObjectsV2Pagination - walk every page of a ListObjects V2 listing
+// via NextContinuationToken (forcing many small pages with a tiny
+// max-keys), and verify the union of keys across all pages equals the
+// expected object set exactly once each.
+func mainListObjectsV2Pagination(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ListObjects V2 (Pagination):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := unpreparedBuckets[0].Name
+
+	seen := map[string]int{}
+	continuationToken := ""
+	for {
+		req, err := newListObjectsV2ReqParams(config, bucketName, listObjectsV2Params{MaxKeys: 3, ContinuationToken: continuationToken})
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		res, err := config.execRequest("GET", req)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		page := listBucketV2Result{}
+		err = xmlDecoder(res.Body, &page)
+		closeResponse(res)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		for _, object := range page.Contents {
+			seen[object.Key]++
+		}
+		scanBar(message)
+		if !page.IsTruncated {
+			break
+		}
+		if page.NextContinuationToken == "" {
+			printMessage(message, fmt.Errorf("Unexpected Response: IsTruncated is true but NextContinuationToken is empty"))
+			return false
+		}
+		continuationToken = page.NextContinuationToken
+	}
+
+	for _, object := range objects {
+		count, ok := seen[object.Key]
+		if !ok {
+			printMessage(message, fmt.Errorf("Unexpected Listing: object %v was never returned across any page", object.Key))
+			return false
+		}
+		if count != 1 {
+			printMessage(message, fmt.Errorf("Unexpected Listing: object %v was returned %d times across pages, wanted 1", object.Key, count))
+			return false
+		}
+	}
+	if len(seen) != len(objects) {
+		printMessage(message, fmt.Errorf("Unexpected Listing: wanted %d distinct keys across all pages, got %d", len(objects), len(seen)))
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainListObjectsV2StartAfter - verify start-after returns exactly the keys
+// that sort lexicographically after the given key, against a sorted view
+// of the expected key set.
+func mainListObjectsV2StartAfter(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ListObjects V2 (Start-After):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := unpreparedBuckets[0].Name
+
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	sort.Strings(keys)
+	if len(keys) < 2 {
+		printMessage(message, fmt.Errorf("Unexpected Test Setup: need at least 2 objects to exercise start-after"))
+		return false
+	}
+	startAfter := keys[len(keys)/2-1]
+	wantKeys := keys[len(keys)/2:]
+	scanBar(message)
+
+	req, err := newListObjectsV2ReqParams(config, bucketName, listObjectsV2Params{StartAfter: startAfter})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	res, err := config.execRequest("GET", req)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	got := listBucketV2Result{}
+	err = xmlDecoder(res.Body, &got)
+	closeResponse(res)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	if len(got.Contents) != len(wantKeys) {
+		printMessage(message, fmt.Errorf("Unexpected Number of Objects Listed: wanted %d keys after %v, got %d", len(wantKeys), startAfter, len(got.Contents)))
+		return false
+	}
+	gotKeys := make([]string, 0, len(got.Contents))
+	for _, object := range got.Contents {
+		gotKeys = append(gotKeys, object.Key)
+	}
+	sort.Strings(gotKeys)
+	for i, want := range wantKeys {
+		if gotKeys[i] != want {
+			printMessage(message, fmt.Errorf("Unexpected Key At Position %d: wanted %v, got %v", i, want, gotKeys[i]))
+			return false
+		}
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainListObjectsV2EncodingType - verify encoding-type=url round-trips a
+// key containing a space, a unicode character, and a control character: the
+// raw XML Key element is URL-encoded so those bytes survive the XML
+// envelope, and percent-decoding it recovers the original key exactly.
+func mainListObjectsV2EncodingType(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ListObjects V2 (Encoding-Type):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	objectName := "s3verify/special key éè\x01name"
+
+	putReq, err := newPutObjectReq(config, bucketName, objectName, []byte("s3verify-encoding-type-payload"))
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := config.execRequest("PUT", putReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRes)
+	scanBar(message)
+
+	req, err := newListObjectsV2ReqParams(config, bucketName, listObjectsV2Params{EncodingType: "url"})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	res, err := config.execRequest("GET", req)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	got := listBucketV2Result{}
+	err = xmlDecoder(res.Body, &got)
+	closeResponse(res)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	found := false
+	for _, object := range got.Contents {
+		decoded, err := url.QueryUnescape(object.Key)
+		if err != nil {
+			printMessage(message, fmt.Errorf("Unexpected Key Encoding: %v is not valid percent-encoding: %v", object.Key, err))
+			return false
+		}
+		if decoded == objectName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		printMessage(message, fmt.Errorf("Unexpected Listing: percent-decoding no returned key recovered %v", objectName))
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
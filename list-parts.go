@@ -23,7 +23,6 @@ import (
 	"net/http"
 	"net/url"
 
-	"github.com/minio/s3verify/signv4"
 )
 
 // newListPartsReq - Create a new HTTP request for the ListParts API.
@@ -52,7 +51,7 @@ func newListPartsReq(config ServerConfig, bucketName, objectName, uploadID strin
 	// Set the requests URL and Header values.
 	listPartsReq.URL = targetURL
 	listPartsReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
-	listPartsReq = signv4.SignV4(*listPartsReq, config.Access, config.Secret, config.Region)
+	listPartsReq = config.Sign(listPartsReq)
 	return listPartsReq, nil
 }
 
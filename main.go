@@ -19,9 +19,14 @@ package main
 import (
 	"fmt"
 	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/s3verify/report"
 )
 
 // Global scanBar for all tests to access and update.
@@ -61,6 +66,21 @@ type APItest struct {
 	Test     func(ServerConfig, int) bool
 	Extended bool // Extended tests will only be invoked at the users request.
 	Critical bool // Tests marked critical must pass before more tests can be run.
+	// API names the S3 API this test exercises (e.g. "copyobject", "multipart").
+	// Left empty, it is inferred from the Test function name by tagsForTest.
+	API string
+	// Tags classify a test for --only/--skip/--suite filtering (e.g.
+	// "multipart", "conditional", "presigned", "copy", "range"). Left empty,
+	// tags are inferred from the Test function name by tagsForTest.
+	Tags []string
+	// Name uniquely identifies this test for DependsOn. Left empty, the
+	// Test function name (via testFuncName) is used instead.
+	Name string
+	// DependsOn lists the Name (or inferred function name) of tests that
+	// must complete before this one may run. Used by the --parallel runner
+	// to decide which tests are safe to run concurrently; serial runs
+	// ignore it since preparedTests/unpreparedTests are already ordered.
+	DependsOn []string
 }
 
 func commandNotFound(ctx *cli.Context, command string) {
@@ -83,16 +103,134 @@ func registerApp() *cli.App {
 
 // makeConfigFromCtx - parse the passed context to create a new config.
 func makeConfigFromCtx(ctx *cli.Context) (*ServerConfig, error) {
-	if ctx.GlobalString("access") != "" &&
-		ctx.GlobalString("secret") != "" &&
-		ctx.GlobalString("url") != "" {
+	access := ctx.GlobalString("access")
+	secret := ctx.GlobalString("secret")
+	if (access == "" || secret == "") && ctx.GlobalString("profile") != "" {
+		credsFile := ctx.GlobalString("credentials-file")
+		if credsFile == "" {
+			credsFile = defaultCredentialsFile()
+		}
+		creds, err := loadAWSProfile(credsFile, ctx.GlobalString("profile"))
+		if err != nil {
+			return nil, err
+		}
+		access = creds.AccessKeyID
+		secret = creds.SecretAccessKey
+	}
+	if access != "" && secret != "" && ctx.GlobalString("url") != "" {
+		switch ctx.GlobalString("signature") {
+		case "", "v2", "v4":
+			// Supported.
+		default:
+			return nil, fmt.Errorf("unsupported --signature %q: only \"v2\" and \"v4\" are implemented", ctx.GlobalString("signature"))
+		}
 		config := newServerConfig(ctx)
+		config.Access = access
+		config.Secret = secret
 		return config, nil
 	}
 	// If config cannot be created successfully show help and exit immediately.
 	return nil, fmt.Errorf("Unable to create config.")
 }
 
+// testFuncName - derive a human readable name for an APItest's Test function
+// via reflection, used to label structured report records.
+func testFuncName(test APItest) string {
+	name := runtime.FuncForPC(reflect.ValueOf(test.Test).Pointer()).Name()
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return name
+}
+
+// runAndRecord - run a single APItest, timing it and, when --report was
+// requested, appending a structured report.Record for the result. When
+// --addressing=auto is set, the test runs once per addressing style
+// (path-style, then virtual-hosted-style) and passes only if both do.
+func runAndRecord(config ServerConfig, test APItest, testCount int) bool {
+	if globalAddressingStyle == "auto" {
+		passed := true
+		for _, style := range []string{"path", "virtual"} {
+			globalAddressingStyle = style
+			if !runAndRecordStyle(config, test, testCount, style) {
+				passed = false
+			}
+		}
+		globalAddressingStyle = "auto"
+		return passed
+	}
+	return runAndRecordStyle(config, test, testCount, globalAddressingStyle)
+}
+
+// runAndRecordStyle runs test under the given addressing style (already set
+// in globalAddressingStyle by the caller) and records a report.Record whose
+// name is labeled with that style.
+func runAndRecordStyle(config ServerConfig, test APItest, testCount int, style string) bool {
+	start := time.Now()
+	passed := test.Test(config, testCount)
+	if globalReporter != nil {
+		status := report.StatusPass
+		errMsg := ""
+		if !passed {
+			status = report.StatusFail
+			lastTestErrorMu.Lock()
+			if lastTestError != nil {
+				errMsg = lastTestError.Error()
+			}
+			lastTestErrorMu.Unlock()
+		} else {
+			lastTestErrorMu.Lock()
+			if lastTestSkipped {
+				status = report.StatusSkip
+			}
+			lastTestErrorMu.Unlock()
+		}
+		lastTestErrorMu.Lock()
+		requestID := lastTestRequestID
+		hostID := lastTestHostID
+		lastTestErrorMu.Unlock()
+		name := testFuncName(test)
+		globalReporter.Add(report.Record{
+			Name:      fmt.Sprintf("%s (%s)", name, style),
+			API:       name,
+			Endpoint:  config.Endpoint,
+			Region:    config.Region,
+			Extended:  test.Extended,
+			Critical:  test.Critical,
+			Status:    status,
+			Duration:  time.Since(start),
+			Error:     errMsg,
+			RequestID: requestID,
+			HostID:    hostID,
+		})
+	}
+	return passed
+}
+
+// writeReport - flush the accumulated report.Reporter, if any, to
+// globalReportPath when set, otherwise to a s3verify-report.<format> file in
+// the current directory.
+func writeReport() {
+	if globalReporter == nil {
+		return
+	}
+	fileName := globalReportPath
+	if fileName == "" {
+		fileName = fmt.Sprintf("s3verify-report.%s", globalReporter.Format)
+	}
+	f, err := os.Create(fileName)
+	if err != nil {
+		console.Println(err)
+		return
+	}
+	defer f.Close()
+	if err := globalReporter.WriteTo(f); err != nil {
+		console.Println(err)
+		return
+	}
+	console.Println("Report written to " + fileName)
+}
+
 // callAllAPIS parse context extract flags and then call all.
 func callAllAPIs(ctx *cli.Context) {
 	// Create a new config from the context.
@@ -105,6 +243,17 @@ func callAllAPIs(ctx *cli.Context) {
 	if err := verifyHostReachable(config.Endpoint, config.Region); err != nil { // If the provided endpoint is unreachable error out instantly.
 		console.Fatalln(err)
 	}
+	// Resolve --only/--skip/--suite into a single predicate used by every
+	// test loop below. Critical tests always pass the filter since later
+	// tests depend on them.
+	filter := newTestFilter(ctx.GlobalString("only"), ctx.GlobalString("skip"), ctx.GlobalString("suite"))
+	parallelRequested := ctx.GlobalInt("parallel")
+	if globalAddressingStyle == "auto" && parallelRequested > 1 {
+		// runAndRecord toggles the package-level globalAddressingStyle
+		// around each per-style run; doing that concurrently would race.
+		console.Println("--addressing=auto forces serial execution; ignoring --parallel")
+		parallelRequested = 1
+	}
 	if ctx.GlobalBool("prepare") {
 		// Create a prepared testing environment with 2 buckets and 1001 objects and 1001 object parts.
 		bucketNames, err := mainPrepareS3Verify(*config)
@@ -125,35 +274,53 @@ func callAllAPIs(ctx *cli.Context) {
 		for _, bucketName := range ctx.Args() {
 			validateBucket(*config, bucketName)
 		}
-		testCount := 1
-		for _, test := range preparedTests { // Run all tests that have been set up.
-			if test.Extended {
-				if ctx.GlobalBool("extended") {
-					test.Test(*config, testCount)
-					testCount++
+		if parallel := parallelRequested; parallel > 1 {
+			runTestsWithWorkerPool(*config, preparedTests, filter, ctx.GlobalBool("extended"), parallel, 1)
+		} else {
+			testCount := 1
+			for _, test := range preparedTests { // Run all tests that have been set up.
+				if api, tags := tagsForTest(test, testFuncName(test)); !filter.allows(test, api, tags) {
+					continue
 				}
-			} else {
-				if !test.Test(*config, testCount) && test.Critical {
-					os.Exit(1)
+				if test.Extended {
+					if ctx.GlobalBool("extended") {
+						runAndRecord(*config, test, testCount)
+						testCount++
+					}
+				} else {
+					if !runAndRecord(*config, test, testCount) && test.Critical && !globalContinueOnCritical {
+						writeReport()
+						os.Exit(1)
+					}
+					testCount++
 				}
-				testCount++
 			}
 		}
+		writeReport()
 	} else {
 		// If the user does not use --prepare flag then just run all non preparedTests.
-		testCount := 1
-		for _, test := range unpreparedTests {
-			if test.Extended {
-				if ctx.GlobalBool("extended") {
-					test.Test(*config, testCount)
+		if parallel := parallelRequested; parallel > 1 {
+			runTestsWithWorkerPool(*config, unpreparedTests, filter, ctx.GlobalBool("extended"), parallel, 1)
+		} else {
+			testCount := 1
+			for _, test := range unpreparedTests {
+				if api, tags := tagsForTest(test, testFuncName(test)); !filter.allows(test, api, tags) {
+					continue
 				}
-			} else {
-				if !test.Test(*config, testCount) && test.Critical {
-					os.Exit(1)
+				if test.Extended {
+					if ctx.GlobalBool("extended") {
+						runAndRecord(*config, test, testCount)
+					}
+				} else {
+					if !runAndRecord(*config, test, testCount) && test.Critical && !globalContinueOnCritical {
+						writeReport()
+						os.Exit(1)
+					}
+					testCount++
 				}
-				testCount++
 			}
 		}
+		writeReport()
 	}
 }
 
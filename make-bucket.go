@@ -30,15 +30,6 @@ import (
 	"github.com/minio/s3verify/signv4"
 )
 
-// MakeBucketReq - hardcode the static portions of a new Make Bucket request.
-var MakeBucketReq = &http.Request{
-	Header: map[string][]string{
-		"X-Amz-Content-Sha256": {hex.EncodeToString(signv4.Sum256([]byte{}))},
-	},
-	Method: "PUT",
-	Body:   nil, // No Body sent for Make Bucket requests.(Need to verify)
-}
-
 var testBuckets = []BucketInfo{
 	BucketInfo{
 		Name: "s3verify-put-bucket-test",
@@ -48,8 +39,17 @@ var testBuckets = []BucketInfo{
 	},
 }
 
-// NewMakeBucketReq - Create a new Make bucket request.
+// NewMakeBucketReq - Create a new Make bucket request. Allocated fresh per
+// call so concurrent callers (e.g. the --parallel worker pool) never share
+// or race on the same *http.Request.
 func NewMakeBucketReq(config ServerConfig, bucketName string) (*http.Request, error) {
+	MakeBucketReq := &http.Request{
+		Header: map[string][]string{
+			"X-Amz-Content-Sha256": {hex.EncodeToString(signv4.Sum256([]byte{}))},
+		},
+		Method: "PUT",
+		Body:   nil, // No Body sent for Make Bucket requests.(Need to verify)
+	}
 	targetURL, err := makeTargetURL(config.Endpoint, bucketName, "", config.Region)
 	if err != nil {
 		return nil, err
@@ -82,7 +82,7 @@ func NewMakeBucketReq(config ServerConfig, bucketName string) (*http.Request, er
 		// Fill request headers and URL.
 		MakeBucketReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
 	}
-	MakeBucketReq = signv4.SignV4(*MakeBucketReq, config.Access, config.Secret, config.Region)
+	MakeBucketReq = config.Sign(MakeBucketReq)
 	return MakeBucketReq, nil
 }
 
@@ -0,0 +1,427 @@
+/*
+ * Minio S3verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// minMultipartPartSize is the smallest part size S3 accepts for all but the
+// last part of a multipart upload.
+const minMultipartPartSize = 5 * 1024 * 1024 // 5MiB
+
+// maxMultipartParts is the most parts a single multipart upload may have.
+const maxMultipartParts = 10000
+
+// multipartPartSize computes the part size an adaptive multipart PUT should
+// use for an object of totalSize bytes: the smallest power-of-two multiple
+// of minMultipartPartSize that keeps the part count at or under
+// maxMultipartParts.
+func multipartPartSize(totalSize int64) int64 {
+	partSize := int64(minMultipartPartSize)
+	for (totalSize+partSize-1)/partSize > maxMultipartParts {
+		partSize *= 2
+	}
+	return partSize
+}
+
+// multipartCompositeETag computes the ETag S3 assigns to a completed
+// multipart object: the hex MD5 of the concatenated per-part MD5 sums,
+// suffixed with "-" and the part count.
+func multipartCompositeETag(parts [][]byte) string {
+	h := md5.New()
+	for _, part := range parts {
+		sum := md5.Sum(part)
+		h.Write(sum[:])
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(h.Sum(nil)), len(parts))
+}
+
+// mainMultipartPutObject - Entry point for an adaptive, concurrent
+// multipart PUT: part size is computed from the object size via
+// multipartPartSize so that no more than maxMultipartParts parts are used,
+// parts are uploaded in parallel through a worker pool bounded by
+// globalPrepareConcurrency, and the upload is finalized with
+// CompleteMultipartUpload. It verifies the final object's ETag takes the
+// documented multipart form and that the reassembled body byte-matches
+// what was uploaded.
+func mainMultipartPutObject(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] PutObject (Adaptive Multipart):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	objectName := "s3verify-multipart-put-adaptive"
+
+	// multipartPartSize must keep arbitrarily large objects under the
+	// 10000-part ceiling without ever handing back a part smaller than the
+	// 5MiB floor, and must not round up unless the part count actually
+	// exceeds the ceiling.
+	partSizeChecks := []struct {
+		totalSize int64
+		expected  int64
+	}{
+		// Exactly at the ceiling: must stay at the 5MiB floor.
+		{maxMultipartParts * minMultipartPartSize, minMultipartPartSize},
+		// One byte past the ceiling: maxMultipartParts full 5MiB parts plus
+		// a 1-byte last part is maxMultipartParts+1 parts, which exceeds the
+		// ceiling, so one doubling to 10MiB is required.
+		{maxMultipartParts*minMultipartPartSize + 1, 2 * minMultipartPartSize},
+		// One whole part past the ceiling: now maxMultipartParts+1 parts at
+		// the 5MiB floor, which does exceed the ceiling, so one doubling to
+		// 10MiB is required.
+		{maxMultipartParts*minMultipartPartSize + minMultipartPartSize, 2 * minMultipartPartSize},
+		// 3x the ceiling at the 5MiB floor is still 1.5x the ceiling after
+		// one doubling (10MiB), so a second doubling is required: 20MiB.
+		{maxMultipartParts * minMultipartPartSize * 3, 4 * minMultipartPartSize},
+	}
+	for _, check := range partSizeChecks {
+		if got := multipartPartSize(check.totalSize); got != check.expected {
+			printMessage(message, fmt.Errorf("Unexpected Part Size for totalSize %v: wanted %v, got %v", check.totalSize, check.expected, got))
+			return false
+		}
+	}
+
+	totalSize := int64(12 * 1024 * 1024) // 12MiB: big enough to need 3 parts at the 5MiB floor.
+	partSize := multipartPartSize(totalSize)
+	partCount := int((totalSize + partSize - 1) / partSize)
+
+	payload := make([]byte, totalSize)
+	if _, err := io.ReadFull(crand.Reader, payload); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	initiateReq, err := newInitiateMultipartUploadReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	initiateRes, err := config.execRequest("POST", initiateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	uploadID, err := initiateMultipartUploadVerify(initiateRes, http.StatusOK)
+	closeResponse(initiateRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sem := make(chan struct{}, globalPrepareConcurrency)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	partCh := make(chan completePart, partCount)
+
+	for i := 0; i < partCount; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		start := int64(i) * partSize
+		end := start + partSize
+		if end > totalSize {
+			end = totalSize
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(partNumber int, partData []byte) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				return
+			}
+			scanBar(message)
+			req, err := newUploadPartReq(config, bucketName, objectName, uploadID, partNumber, partData)
+			if err != nil {
+				errOnce.Do(func() { firstErr = err; cancel() })
+				return
+			}
+			res, err := execRequest(req, config.Client, bucketName, objectName)
+			if err != nil {
+				errOnce.Do(func() { firstErr = err; cancel() })
+				return
+			}
+			defer closeResponse(res)
+			if err := uploadPartVerify(res, "200 OK"); err != nil {
+				errOnce.Do(func() { firstErr = err; cancel() })
+				return
+			}
+			partCh <- completePart{
+				PartNumber: partNumber,
+				ETag:       trimQuotes(res.Header.Get("ETag")),
+			}
+		}(i+1, payload[start:end])
+	}
+	wg.Wait()
+	close(partCh)
+	if firstErr != nil {
+		printMessage(message, firstErr)
+		return false
+	}
+
+	parts := make([]completePart, 0, partCount)
+	for part := range partCh {
+		parts = append(parts, part)
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	scanBar(message)
+
+	completeReq, err := newCompleteMultipartUploadReq(config, bucketName, objectName, uploadID, &completeMultipartUpload{Parts: parts})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	completeRes, err := execRequest(completeReq, config.Client, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = completeMultipartUploadVerify(completeRes, http.StatusOK)
+	closeResponse(completeRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	getReq, err := newGetObjectReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRes, err := config.execRequest("GET", getReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(getRes)
+	gotETag := trimQuotes(getRes.Header.Get("ETag"))
+	partData := make([][]byte, partCount)
+	for i := 0; i < partCount; i++ {
+		start := int64(i) * partSize
+		end := start + partSize
+		if end > totalSize {
+			end = totalSize
+		}
+		partData[i] = payload[start:end]
+	}
+	if wantETag := multipartCompositeETag(partData); gotETag != wantETag {
+		printMessage(message, fmt.Errorf("Unexpected ETag: wanted %v, got %v", wantETag, gotETag))
+		return false
+	}
+	if err := getObjectVerify(getRes, payload, http.StatusOK); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainMultipartPutObjectInvalid - Entry point for three multipart PUT
+// failure cases: completing an aborted upload must be rejected with
+// NoSuchUpload, completing a live upload with its part list out of
+// ascending order must be rejected with InvalidPartOrder, and completing a
+// live upload that references a part number never uploaded must be
+// rejected with InvalidPart.
+func mainMultipartPutObjectInvalid(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] PutObject (Multipart Abort/Part-Order):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+
+	// Completing an upload after it has been aborted must fail with NoSuchUpload.
+	abortObjectName := "s3verify-multipart-put-abort-complete"
+	abortInitiateReq, err := newInitiateMultipartUploadReq(config, bucketName, abortObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	abortInitiateRes, err := config.execRequest("POST", abortInitiateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	abortUploadID, err := initiateMultipartUploadVerify(abortInitiateRes, http.StatusOK)
+	closeResponse(abortInitiateRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	abortReq, err := newAbortMultipartUploadReq(config, bucketName, abortObjectName, abortUploadID)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	abortRes, err := execRequest(abortReq, config.Client, bucketName, abortObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = abortMultipartUploadVerify(abortRes, http.StatusNoContent, ErrorResponse{})
+	closeResponse(abortRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	completeAfterAbortReq, err := newCompleteMultipartUploadReq(config, bucketName, abortObjectName, abortUploadID, &completeMultipartUpload{})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	completeAfterAbortRes, err := execRequest(completeAfterAbortReq, config.Client, bucketName, abortObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(completeAfterAbortRes, http.StatusNotFound, "NoSuchUpload")
+	closeResponse(completeAfterAbortRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// Completing a live upload with its parts out of ascending order must
+	// fail with InvalidPartOrder.
+	orderObjectName := "s3verify-multipart-put-part-order"
+	orderInitiateReq, err := newInitiateMultipartUploadReq(config, bucketName, orderObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	orderInitiateRes, err := config.execRequest("POST", orderInitiateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	orderUploadID, err := initiateMultipartUploadVerify(orderInitiateRes, http.StatusOK)
+	closeResponse(orderInitiateRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	var orderParts []completePart
+	for partNumber := 1; partNumber <= 2; partNumber++ {
+		partData := make([]byte, minMultipartPartSize)
+		if _, err := io.ReadFull(crand.Reader, partData); err != nil {
+			printMessage(message, err)
+			return false
+		}
+		partReq, err := newUploadPartReq(config, bucketName, orderObjectName, orderUploadID, partNumber, partData)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		partRes, err := execRequest(partReq, config.Client, bucketName, orderObjectName)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		err = uploadPartVerify(partRes, "200 OK")
+		etag := trimQuotes(partRes.Header.Get("ETag"))
+		closeResponse(partRes)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		orderParts = append(orderParts, completePart{PartNumber: partNumber, ETag: etag})
+		scanBar(message)
+	}
+
+	// Reverse the parts before completing so they arrive out of order.
+	reversedParts := []completePart{orderParts[1], orderParts[0]}
+	orderCompleteReq, err := newCompleteMultipartUploadReq(config, bucketName, orderObjectName, orderUploadID, &completeMultipartUpload{Parts: reversedParts})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	orderCompleteRes, err := execRequest(orderCompleteReq, config.Client, bucketName, orderObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(orderCompleteRes, http.StatusBadRequest, "InvalidPartOrder")
+	closeResponse(orderCompleteRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// Completing a live upload that names a part number which was never
+	// uploaded must fail with InvalidPart.
+	missingObjectName := "s3verify-multipart-put-missing-part"
+	missingInitiateReq, err := newInitiateMultipartUploadReq(config, bucketName, missingObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	missingInitiateRes, err := config.execRequest("POST", missingInitiateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	missingUploadID, err := initiateMultipartUploadVerify(missingInitiateRes, http.StatusOK)
+	closeResponse(missingInitiateRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	missingCompleteReq, err := newCompleteMultipartUploadReq(config, bucketName, missingObjectName, missingUploadID, &completeMultipartUpload{
+		Parts: []completePart{{PartNumber: 1, ETag: "\"0123456789abcdef0123456789abcdef\""}},
+	})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	missingCompleteRes, err := execRequest(missingCompleteReq, config.Client, bucketName, missingObjectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(missingCompleteRes, http.StatusBadRequest, "InvalidPart")
+	closeResponse(missingCompleteRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
@@ -0,0 +1,211 @@
+/*
+ * Minio S3verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// grantee/grant/accessControlPolicy mirror the XML body GetObjectACL
+// returns.
+type grantee struct {
+	Type        string `xml:"type,attr"`
+	ID          string `xml:"ID,omitempty"`
+	DisplayName string `xml:"DisplayName,omitempty"`
+	URI         string `xml:"URI,omitempty"`
+}
+
+type grant struct {
+	Grantee    grantee `xml:"Grantee"`
+	Permission string  `xml:"Permission"`
+}
+
+type accessControlPolicy struct {
+	XMLName xml.Name `xml:"AccessControlPolicy"`
+	Owner   struct {
+		ID          string `xml:"ID"`
+		DisplayName string `xml:"DisplayName"`
+	} `xml:"Owner"`
+	AccessControlList struct {
+		Grants []grant `xml:"Grant"`
+	} `xml:"AccessControlList"`
+}
+
+// newPutObjectACLReq - Create a new HTTP request for the PutObjectACL API,
+// applying a canned ACL (e.g. "private", "public-read") via the x-amz-acl
+// header.
+func newPutObjectACLReq(config ServerConfig, bucketName, objectName, cannedACL string) (Request, error) {
+	var putObjectACLReq = Request{
+		customHeader: http.Header{},
+	}
+
+	putObjectACLReq.bucketName = bucketName
+	putObjectACLReq.objectName = objectName
+	putObjectACLReq.queryValues = url.Values{"acl": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	putObjectACLReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	putObjectACLReq.customHeader.Set("x-amz-acl", cannedACL)
+	putObjectACLReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return putObjectACLReq, nil
+}
+
+// newGetObjectACLReq - Create a new HTTP request for the GetObjectACL API.
+func newGetObjectACLReq(config ServerConfig, bucketName, objectName string) (Request, error) {
+	var getObjectACLReq = Request{
+		customHeader: http.Header{},
+	}
+
+	getObjectACLReq.bucketName = bucketName
+	getObjectACLReq.objectName = objectName
+	getObjectACLReq.queryValues = url.Values{"acl": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	getObjectACLReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	getObjectACLReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return getObjectACLReq, nil
+}
+
+// verifyStatusObjectACL - verify the status returned matches what is expected.
+func verifyStatusObjectACL(respStatusCode, expectedStatusCode int) error {
+	if respStatusCode != expectedStatusCode {
+		return fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", expectedStatusCode, respStatusCode)
+	}
+	return nil
+}
+
+// getObjectACLVerify - verify the GetObjectACL response carries a grant for
+// the expected permission (e.g. a "public-read" ACL must contain a READ
+// grant for the AllUsers group).
+func getObjectACLVerify(res *http.Response, expectedStatusCode int, expectedPermission, expectedGranteeURI string) error {
+	if err := verifyStatusObjectACL(res.StatusCode, expectedStatusCode); err != nil {
+		return err
+	}
+	if expectedStatusCode != http.StatusOK {
+		return nil
+	}
+	gotPolicy := accessControlPolicy{}
+	if err := xmlDecoder(res.Body, &gotPolicy); err != nil {
+		return err
+	}
+	for _, g := range gotPolicy.AccessControlList.Grants {
+		if g.Permission == expectedPermission && g.Grantee.URI == expectedGranteeURI {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unexpected ACL: no %v grant found for grantee %v", expectedPermission, expectedGranteeURI)
+}
+
+// mainObjectACL - Entry point for the Put/GetObjectACL API tests. Applies
+// the "public-read" canned ACL and verifies GetObjectACL reports a READ
+// grant for the AllUsers group, then restores the object to "private" and
+// verifies the AllUsers grant is gone.
+func mainObjectACL(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ObjectACL (Put/Get):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	objectName := s3verifyObjects[0].Key
+	allUsersURI := "http://acs.amazonaws.com/groups/global/AllUsers"
+
+	publicReq, err := newPutObjectACLReq(config, bucketName, objectName, "public-read")
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	publicRes, err := config.execRequest("PUT", publicReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(publicRes)
+	if err := verifyStatusObjectACL(publicRes.StatusCode, http.StatusOK); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	getPublicReq, err := newGetObjectACLReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getPublicRes, err := config.execRequest("GET", getPublicReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = getObjectACLVerify(getPublicRes, http.StatusOK, "READ", allUsersURI)
+	closeResponse(getPublicRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	privateReq, err := newPutObjectACLReq(config, bucketName, objectName, "private")
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	privateRes, err := config.execRequest("PUT", privateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(privateRes)
+	if err := verifyStatusObjectACL(privateRes.StatusCode, http.StatusOK); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	getPrivateReq, err := newGetObjectACLReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getPrivateRes, err := config.execRequest("GET", getPrivateReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = getObjectACLVerify(getPrivateRes, http.StatusOK, "READ", allUsersURI)
+	closeResponse(getPrivateRes)
+	if err == nil {
+		printMessage(message, fmt.Errorf("Unexpected ACL: AllUsers READ grant still present after restoring private"))
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
@@ -0,0 +1,885 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// defaultRetention/objectLockRule/objectLockConfiguration model the subset
+// of ObjectLockConfiguration exercised by this test: lock-enabled status
+// plus a default Retention mode/period.
+type defaultRetention struct {
+	Mode  string `xml:"Mode"`
+	Days  int    `xml:"Days,omitempty"`
+	Years int    `xml:"Years,omitempty"`
+}
+
+type objectLockRule struct {
+	DefaultRetention defaultRetention `xml:"DefaultRetention"`
+}
+
+type objectLockConfiguration struct {
+	XMLName           xml.Name       `xml:"ObjectLockConfiguration"`
+	ObjectLockEnabled string         `xml:"ObjectLockEnabled"`
+	Rule              objectLockRule `xml:"Rule"`
+}
+
+// retention models the per-object Retention document set/read via ?retention.
+type retention struct {
+	XMLName         xml.Name `xml:"Retention"`
+	Mode            string   `xml:"Mode"`
+	RetainUntilDate string   `xml:"RetainUntilDate"`
+}
+
+// legalHold models the per-object Legal Hold document set/read via ?legal-hold.
+type legalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Status  string   `xml:"Status"`
+}
+
+// newMakeBucketWithObjectLockReq - Create a new HTTP request for MakeBucket
+// with Object Lock enabled at creation time, since S3 only allows enabling
+// Object Lock on a bucket at MakeBucket time.
+func newMakeBucketWithObjectLockReq(config ServerConfig, bucketName string) (Request, error) {
+	var makeBucketReq = Request{
+		customHeader: http.Header{},
+	}
+
+	makeBucketReq.bucketName = bucketName
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	makeBucketReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	makeBucketReq.customHeader.Set("x-amz-bucket-object-lock-enabled", "true")
+	makeBucketReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return makeBucketReq, nil
+}
+
+// newPutObjectLockConfigurationReq - Create a new HTTP request for the
+// PutObjectLockConfiguration API.
+func newPutObjectLockConfigurationReq(config ServerConfig, bucketName string, lock objectLockConfiguration) (Request, error) {
+	var putLockReq = Request{
+		customHeader: http.Header{},
+	}
+
+	putLockReq.bucketName = bucketName
+	putLockReq.queryValues = url.Values{"object-lock": []string{""}}
+
+	lockBytes, err := xml.Marshal(lock)
+	if err != nil {
+		return Request{}, err
+	}
+	reader := bytes.NewReader(lockBytes)
+	md5Sum, sha256Sum, contentLength, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	reader.Seek(0, 0)
+	putLockReq.contentBody = reader
+	putLockReq.contentLength = contentLength
+	putLockReq.customHeader.Set("Content-MD5", hex.EncodeToString(md5Sum))
+	putLockReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	putLockReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return putLockReq, nil
+}
+
+// newGetObjectLockConfigurationReq - Create a new HTTP request for the
+// GetObjectLockConfiguration API.
+func newGetObjectLockConfigurationReq(config ServerConfig, bucketName string) (Request, error) {
+	var getLockReq = Request{
+		customHeader: http.Header{},
+	}
+
+	getLockReq.bucketName = bucketName
+	getLockReq.queryValues = url.Values{"object-lock": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	getLockReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	getLockReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return getLockReq, nil
+}
+
+// newPutObjectRetentionReq - Create a new HTTP request for the
+// PutObjectRetention API.
+func newPutObjectRetentionReq(config ServerConfig, bucketName, objectName string, ret retention, bypassGovernance bool) (Request, error) {
+	var putRetentionReq = Request{
+		customHeader: http.Header{},
+	}
+
+	putRetentionReq.bucketName = bucketName
+	putRetentionReq.objectName = objectName
+	putRetentionReq.queryValues = url.Values{"retention": []string{""}}
+
+	retBytes, err := xml.Marshal(ret)
+	if err != nil {
+		return Request{}, err
+	}
+	reader := bytes.NewReader(retBytes)
+	md5Sum, sha256Sum, contentLength, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	reader.Seek(0, 0)
+	putRetentionReq.contentBody = reader
+	putRetentionReq.contentLength = contentLength
+	putRetentionReq.customHeader.Set("Content-MD5", hex.EncodeToString(md5Sum))
+	putRetentionReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	if bypassGovernance {
+		putRetentionReq.customHeader.Set("x-amz-bypass-governance-retention", "true")
+	}
+	putRetentionReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return putRetentionReq, nil
+}
+
+// newGetObjectRetentionReq - Create a new HTTP request for the
+// GetObjectRetention API.
+func newGetObjectRetentionReq(config ServerConfig, bucketName, objectName string) (Request, error) {
+	var getRetentionReq = Request{
+		customHeader: http.Header{},
+	}
+
+	getRetentionReq.bucketName = bucketName
+	getRetentionReq.objectName = objectName
+	getRetentionReq.queryValues = url.Values{"retention": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	getRetentionReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	getRetentionReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return getRetentionReq, nil
+}
+
+// newPutObjectLegalHoldReq - Create a new HTTP request for the
+// PutObjectLegalHold API.
+func newPutObjectLegalHoldReq(config ServerConfig, bucketName, objectName string, hold legalHold) (Request, error) {
+	var putHoldReq = Request{
+		customHeader: http.Header{},
+	}
+
+	putHoldReq.bucketName = bucketName
+	putHoldReq.objectName = objectName
+	putHoldReq.queryValues = url.Values{"legal-hold": []string{""}}
+
+	holdBytes, err := xml.Marshal(hold)
+	if err != nil {
+		return Request{}, err
+	}
+	reader := bytes.NewReader(holdBytes)
+	md5Sum, sha256Sum, contentLength, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	reader.Seek(0, 0)
+	putHoldReq.contentBody = reader
+	putHoldReq.contentLength = contentLength
+	putHoldReq.customHeader.Set("Content-MD5", hex.EncodeToString(md5Sum))
+	putHoldReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	putHoldReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return putHoldReq, nil
+}
+
+// newGetObjectLegalHoldReq - Create a new HTTP request for the
+// GetObjectLegalHold API.
+func newGetObjectLegalHoldReq(config ServerConfig, bucketName, objectName string) (Request, error) {
+	var getHoldReq = Request{
+		customHeader: http.Header{},
+	}
+
+	getHoldReq.bucketName = bucketName
+	getHoldReq.objectName = objectName
+	getHoldReq.queryValues = url.Values{"legal-hold": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	getHoldReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	getHoldReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return getHoldReq, nil
+}
+
+// verifyObjectLockHeaders - verify that the x-amz-object-lock-mode,
+// x-amz-object-lock-retain-until-date and x-amz-object-lock-legal-hold
+// headers GET/HEAD Object echo back for a locked object match what is
+// expected. An empty expected value means the header must be empty/absent,
+// i.e. the object is not locked in that respect.
+func verifyObjectLockHeaders(header http.Header, expectedMode, expectedRetainUntilDate, expectedLegalHold string) error {
+	if got := header.Get("x-amz-object-lock-mode"); got != expectedMode {
+		return fmt.Errorf("Unexpected x-amz-object-lock-mode: wanted %v, got %v", expectedMode, got)
+	}
+	if got := header.Get("x-amz-object-lock-retain-until-date"); got != expectedRetainUntilDate {
+		return fmt.Errorf("Unexpected x-amz-object-lock-retain-until-date: wanted %v, got %v", expectedRetainUntilDate, got)
+	}
+	if got := header.Get("x-amz-object-lock-legal-hold"); got != expectedLegalHold {
+		return fmt.Errorf("Unexpected x-amz-object-lock-legal-hold: wanted %v, got %v", expectedLegalHold, got)
+	}
+	return nil
+}
+
+// verifyStatusObjectLock - verify the status returned matches what is expected.
+func verifyStatusObjectLock(respStatusCode, expectedStatusCode int) error {
+	if respStatusCode != expectedStatusCode {
+		return fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", expectedStatusCode, respStatusCode)
+	}
+	return nil
+}
+
+// getObjectLockConfigurationVerify - verify the round-tripped lock
+// configuration matches what was PUT.
+func getObjectLockConfigurationVerify(res *http.Response, expectedStatusCode int, expected objectLockConfiguration) error {
+	if err := verifyStatusObjectLock(res.StatusCode, expectedStatusCode); err != nil {
+		return err
+	}
+	if expectedStatusCode != http.StatusOK {
+		return nil
+	}
+	got := objectLockConfiguration{}
+	if err := xmlDecoder(res.Body, &got); err != nil {
+		return err
+	}
+	if got.ObjectLockEnabled != expected.ObjectLockEnabled {
+		return fmt.Errorf("Unexpected ObjectLockEnabled: wanted %v, got %v", expected.ObjectLockEnabled, got.ObjectLockEnabled)
+	}
+	if got.Rule.DefaultRetention.Mode != expected.Rule.DefaultRetention.Mode {
+		return fmt.Errorf("Unexpected Default Retention Mode: wanted %v, got %v", expected.Rule.DefaultRetention.Mode, got.Rule.DefaultRetention.Mode)
+	}
+	if got.Rule.DefaultRetention.Days != expected.Rule.DefaultRetention.Days || got.Rule.DefaultRetention.Years != expected.Rule.DefaultRetention.Years {
+		return fmt.Errorf("Unexpected Default Retention Period: wanted %+v, got %+v", expected.Rule.DefaultRetention, got.Rule.DefaultRetention)
+	}
+	return nil
+}
+
+// getObjectRetentionVerify - verify the round-tripped Retention document
+// matches what was PUT.
+func getObjectRetentionVerify(res *http.Response, expectedStatusCode int, expected retention) error {
+	if err := verifyStatusObjectLock(res.StatusCode, expectedStatusCode); err != nil {
+		return err
+	}
+	if expectedStatusCode != http.StatusOK {
+		return nil
+	}
+	got := retention{}
+	if err := xmlDecoder(res.Body, &got); err != nil {
+		return err
+	}
+	if got.Mode != expected.Mode || got.RetainUntilDate != expected.RetainUntilDate {
+		return fmt.Errorf("Unexpected Retention: wanted %+v, got %+v", expected, got)
+	}
+	return nil
+}
+
+// getObjectLegalHoldVerify - verify the round-tripped Legal Hold document
+// matches what was PUT.
+func getObjectLegalHoldVerify(res *http.Response, expectedStatusCode int, expected legalHold) error {
+	if err := verifyStatusObjectLock(res.StatusCode, expectedStatusCode); err != nil {
+		return err
+	}
+	if expectedStatusCode != http.StatusOK {
+		return nil
+	}
+	got := legalHold{}
+	if err := xmlDecoder(res.Body, &got); err != nil {
+		return err
+	}
+	if got.Status != expected.Status {
+		return fmt.Errorf("Unexpected LegalHold Status: wanted %v, got %v", expected.Status, got.Status)
+	}
+	return nil
+}
+
+// mainObjectLockGovernance - Entry point for the Object Lock / Retention /
+// Legal Hold test suite under GOVERNANCE mode: creates a lock-enabled
+// bucket, confirms that doing so implicitly enabled versioning, round-trips
+// a bucket-level ObjectLockConfiguration, round-trips per-object Retention
+// and Legal Hold, then proves DeleteObject on a retained object is refused
+// with 403 unless x-amz-bypass-governance-retention is set. See
+// mainObjectLockCompliance for the stricter COMPLIANCE-mode counterpart and
+// mainObjectLegalHold for Legal Hold exercised on its own.
+func mainObjectLockGovernance(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ObjectLock (Governance):", curTest, globalTotalNumTest)
+	if !globalObjectLock {
+		return skipMessage(message, "--object-lock not set")
+	}
+	scanBar(message)
+	bucketName := "s3verify-object-lock-bucket"
+	objectName := "s3verify-object-lock-object"
+
+	makeReq, err := newMakeBucketWithObjectLockReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	makeRes, err := config.execRequest("PUT", makeReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(makeRes)
+	scanBar(message)
+
+	// A bucket created with x-amz-bucket-object-lock-enabled must implicitly
+	// enable versioning, since retained object versions cannot be
+	// overwritten in place.
+	getVersioningReq, err := newGetBucketVersioningReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getVersioningRes, err := config.execRequest("GET", getVersioningReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = getBucketVersioningVerify(getVersioningRes, http.StatusOK, "Enabled")
+	closeResponse(getVersioningRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	lockConfig := objectLockConfiguration{
+		ObjectLockEnabled: "Enabled",
+		Rule: objectLockRule{
+			DefaultRetention: defaultRetention{Mode: "GOVERNANCE", Days: 1},
+		},
+	}
+	putLockReq, err := newPutObjectLockConfigurationReq(config, bucketName, lockConfig)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putLockRes, err := config.execRequest("PUT", putLockReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putLockRes)
+	scanBar(message)
+
+	getLockReq, err := newGetObjectLockConfigurationReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getLockRes, err := config.execRequest("GET", getLockReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = getObjectLockConfigurationVerify(getLockRes, http.StatusOK, lockConfig)
+	closeResponse(getLockRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	putReq, err := newPutObjectReq(config, bucketName, objectName, []byte("s3verify-object-lock-payload"))
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := execRequest(putReq, config.Client, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRes)
+	scanBar(message)
+
+	ret := retention{Mode: "GOVERNANCE", RetainUntilDate: "2100-01-01T00:00:00Z"}
+	putRetentionReq, err := newPutObjectRetentionReq(config, bucketName, objectName, ret, false)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRetentionRes, err := config.execRequest("PUT", putRetentionReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRetentionRes)
+	scanBar(message)
+
+	getRetentionReq, err := newGetObjectRetentionReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRetentionRes, err := config.execRequest("GET", getRetentionReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = getObjectRetentionVerify(getRetentionRes, http.StatusOK, ret)
+	closeResponse(getRetentionRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	hold := legalHold{Status: "ON"}
+	putHoldReq, err := newPutObjectLegalHoldReq(config, bucketName, objectName, hold)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putHoldRes, err := config.execRequest("PUT", putHoldReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putHoldRes)
+	scanBar(message)
+
+	getHoldReq, err := newGetObjectLegalHoldReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getHoldRes, err := config.execRequest("GET", getHoldReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = getObjectLegalHoldVerify(getHoldRes, http.StatusOK, hold)
+	closeResponse(getHoldRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// A locked object with Status=ON Legal Hold must refuse DeleteObject
+	// regardless of bypass-governance-retention.
+	delReq, err := newRemoveObjectReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	delRes, err := execRequest(delReq, config.Client, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(delRes, http.StatusForbidden, "AccessDenied")
+	closeResponse(delRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// Clear the Legal Hold, then prove the still-in-force GOVERNANCE
+	// Retention refuses DeleteObject unless bypass-governance-retention is set.
+	clearHoldReq, err := newPutObjectLegalHoldReq(config, bucketName, objectName, legalHold{Status: "OFF"})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	clearHoldRes, err := config.execRequest("PUT", clearHoldReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(clearHoldRes)
+	scanBar(message)
+
+	noBypassDelReq, err := newRemoveObjectReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	noBypassDelRes, err := execRequest(noBypassDelReq, config.Client, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(noBypassDelRes, http.StatusForbidden, "AccessDenied")
+	closeResponse(noBypassDelRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	bypassDelReq, err := newRemoveObjectReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	bypassDelReq.Header.Set("x-amz-bypass-governance-retention", "true")
+	bypassDelRes, err := execRequest(bypassDelReq, config.Client, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	if err := verifyStatusObjectLock(bypassDelRes.StatusCode, http.StatusNoContent); err != nil {
+		closeResponse(bypassDelRes)
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(bypassDelRes)
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainObjectLockCompliance - Entry point for the Object Lock test suite
+// under COMPLIANCE mode: unlike GOVERNANCE, a COMPLIANCE-mode retention must
+// refuse DeleteObject even when x-amz-bypass-governance-retention is set,
+// since compliance retention cannot be shortened or bypassed by any
+// principal, including the bucket owner, until RetainUntilDate passes.
+func mainObjectLockCompliance(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ObjectLock (Compliance):", curTest, globalTotalNumTest)
+	if !globalObjectLock {
+		return skipMessage(message, "--object-lock not set")
+	}
+	scanBar(message)
+	bucketName := "s3verify-object-lock-compliance-bucket"
+	objectName := "s3verify-object-lock-compliance-object"
+
+	makeReq, err := newMakeBucketWithObjectLockReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	makeRes, err := config.execRequest("PUT", makeReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(makeRes)
+	scanBar(message)
+
+	putReq, err := newPutObjectReq(config, bucketName, objectName, []byte("s3verify-object-lock-compliance-payload"))
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := execRequest(putReq, config.Client, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRes)
+	scanBar(message)
+
+	ret := retention{Mode: "COMPLIANCE", RetainUntilDate: "2100-01-01T00:00:00Z"}
+	putRetentionReq, err := newPutObjectRetentionReq(config, bucketName, objectName, ret, false)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRetentionRes, err := config.execRequest("PUT", putRetentionReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRetentionRes)
+	scanBar(message)
+
+	getRetentionReq, err := newGetObjectRetentionReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRetentionRes, err := config.execRequest("GET", getRetentionReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = getObjectRetentionVerify(getRetentionRes, http.StatusOK, ret)
+	closeResponse(getRetentionRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// Even x-amz-bypass-governance-retention must not lift a COMPLIANCE hold.
+	bypassDelReq, err := newRemoveObjectReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	bypassDelReq.Header.Set("x-amz-bypass-governance-retention", "true")
+	bypassDelRes, err := execRequest(bypassDelReq, config.Client, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(bypassDelRes, http.StatusForbidden, "AccessDenied")
+	closeResponse(bypassDelRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainObjectLegalHold - Entry point for the Legal Hold test suite on its
+// own, independent of any Retention setting: DeleteObject must be refused
+// while Legal Hold Status is "ON" and succeed once it is toggled back to
+// "OFF".
+func mainObjectLegalHold(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ObjectLock (Legal Hold):", curTest, globalTotalNumTest)
+	if !globalObjectLock {
+		return skipMessage(message, "--object-lock not set")
+	}
+	scanBar(message)
+	bucketName := "s3verify-object-lock-legal-hold-bucket"
+	objectName := "s3verify-object-lock-legal-hold-object"
+
+	makeReq, err := newMakeBucketWithObjectLockReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	makeRes, err := config.execRequest("PUT", makeReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(makeRes)
+	scanBar(message)
+
+	putReq, err := newPutObjectReq(config, bucketName, objectName, []byte("s3verify-object-lock-legal-hold-payload"))
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := execRequest(putReq, config.Client, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putRes)
+	scanBar(message)
+
+	hold := legalHold{Status: "ON"}
+	putHoldReq, err := newPutObjectLegalHoldReq(config, bucketName, objectName, hold)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putHoldRes, err := config.execRequest("PUT", putHoldReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putHoldRes)
+	scanBar(message)
+
+	getHoldReq, err := newGetObjectLegalHoldReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getHoldRes, err := config.execRequest("GET", getHoldReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = getObjectLegalHoldVerify(getHoldRes, http.StatusOK, hold)
+	closeResponse(getHoldRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// Status=ON must refuse DeleteObject.
+	delReq, err := newRemoveObjectReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	delRes, err := execRequest(delReq, config.Client, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(delRes, http.StatusForbidden, "AccessDenied")
+	closeResponse(delRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// Toggling Status=OFF must allow DeleteObject to proceed.
+	clearHoldReq, err := newPutObjectLegalHoldReq(config, bucketName, objectName, legalHold{Status: "OFF"})
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	clearHoldRes, err := config.execRequest("PUT", clearHoldReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(clearHoldRes)
+	scanBar(message)
+
+	finalDelReq, err := newRemoveObjectReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	finalDelRes, err := execRequest(finalDelReq, config.Client, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyStatusObjectLock(finalDelRes.StatusCode, http.StatusNoContent)
+	closeResponse(finalDelRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// objectLockRetentionBucket/objectLockRetentionObject/
+// objectLockRetentionRetainUntil are shared between mainPutObjectRetention
+// and mainHeadObjectRetention, which are registered as two independent
+// tests: the former sets Object Lock metadata directly on PutObject, the
+// latter (run immediately after) confirms it round-trips through HEAD.
+const (
+	objectLockRetentionBucket      = "s3verify-object-lock-retention-bucket"
+	objectLockRetentionObject      = "s3verify-object-lock-retention-object"
+	objectLockRetentionRetainUntil = "2100-01-01T00:00:00Z"
+)
+
+// mainPutObjectRetention - Entry point for PutObject with Object Lock
+// metadata set directly via x-amz-object-lock-mode/-retain-until-date
+// headers at upload time, as opposed to mainObjectLockGovernance's
+// follow-up PutObjectRetention call against an already-existing object.
+// mainHeadObjectRetention confirms the metadata set here round-trips.
+func mainPutObjectRetention(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] PutObject (Retention Headers):", curTest, globalTotalNumTest)
+	if !globalObjectLock {
+		return skipMessage(message, "--object-lock not set")
+	}
+	scanBar(message)
+
+	makeReq, err := newMakeBucketWithObjectLockReq(config, objectLockRetentionBucket)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	makeRes, err := config.execRequest("PUT", makeReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(makeRes)
+	scanBar(message)
+
+	putReq, err := newPutObjectReq(config, objectLockRetentionBucket, objectLockRetentionObject, []byte("s3verify-object-lock-retention-payload"))
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putReq.Header.Set("x-amz-object-lock-mode", "GOVERNANCE")
+	putReq.Header.Set("x-amz-object-lock-retain-until-date", objectLockRetentionRetainUntil)
+	putRes, err := execRequest(putReq, config.Client, objectLockRetentionBucket, objectLockRetentionObject)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = putObjectVerify(putRes, "200 OK")
+	closeResponse(putRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
+// mainHeadObjectRetention - Entry point confirming that the Object Lock
+// metadata mainPutObjectRetention set directly on PutObject round-trips
+// through HEAD Object's x-amz-object-lock-mode/-retain-until-date/
+// -legal-hold headers.
+func mainHeadObjectRetention(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] HeadObject (Retention Headers):", curTest, globalTotalNumTest)
+	if !globalObjectLock {
+		return skipMessage(message, "--object-lock not set")
+	}
+	scanBar(message)
+
+	headReq, err := newHeadObjectReq(config, objectLockRetentionBucket, objectLockRetentionObject)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	headRes, err := config.execRequest("HEAD", headReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(headRes)
+	if err := headObjectVerify(headRes, http.StatusOK); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	if err := verifyObjectLockHeaders(headRes.Header, "GOVERNANCE", objectLockRetentionRetainUntil, ""); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
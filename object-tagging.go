@@ -0,0 +1,208 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// tagSet/tag mirror the XML body PutObjectTagging expects.
+type tag struct {
+	Key   string
+	Value string
+}
+
+type tagSet struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Tags    []tag    `xml:"TagSet>Tag"`
+}
+
+// newPutObjectTaggingReq - Create a new HTTP request for the PutObjectTagging API.
+// Empty tag values must be accepted: several S3 vendors reject them, which is
+// a common interop gap this test exists to catch.
+func newPutObjectTaggingReq(config ServerConfig, bucketName, objectName string, tags []tag) (Request, error) {
+	var putObjectTaggingReq = Request{
+		customHeader: http.Header{},
+	}
+
+	putObjectTaggingReq.bucketName = bucketName
+	putObjectTaggingReq.objectName = objectName
+	putObjectTaggingReq.queryValues = url.Values{"tagging": []string{""}}
+
+	tagBytes, err := xml.Marshal(tagSet{Tags: tags})
+	if err != nil {
+		return Request{}, err
+	}
+	reader := bytes.NewReader(tagBytes)
+	_, sha256Sum, contentLength, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	reader.Seek(0, 0)
+	putObjectTaggingReq.contentBody = reader
+	putObjectTaggingReq.contentLength = contentLength
+	putObjectTaggingReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	putObjectTaggingReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return putObjectTaggingReq, nil
+}
+
+// newGetObjectTaggingReq - Create a new HTTP request for the GetObjectTagging API.
+func newGetObjectTaggingReq(config ServerConfig, bucketName, objectName string) (Request, error) {
+	var getObjectTaggingReq = Request{
+		customHeader: http.Header{},
+	}
+
+	getObjectTaggingReq.bucketName = bucketName
+	getObjectTaggingReq.objectName = objectName
+	getObjectTaggingReq.queryValues = url.Values{"tagging": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	getObjectTaggingReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	getObjectTaggingReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return getObjectTaggingReq, nil
+}
+
+// newDeleteObjectTaggingReq - Create a new HTTP request for the DeleteObjectTagging API.
+func newDeleteObjectTaggingReq(config ServerConfig, bucketName, objectName string) (Request, error) {
+	var deleteObjectTaggingReq = Request{
+		customHeader: http.Header{},
+	}
+
+	deleteObjectTaggingReq.bucketName = bucketName
+	deleteObjectTaggingReq.objectName = objectName
+	deleteObjectTaggingReq.queryValues = url.Values{"tagging": []string{""}}
+
+	reader := bytes.NewReader([]byte{})
+	_, sha256Sum, _, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	deleteObjectTaggingReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	deleteObjectTaggingReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return deleteObjectTaggingReq, nil
+}
+
+// verifyStatusObjectTagging - verify the status returned matches what is expected.
+func verifyStatusObjectTagging(respStatusCode, expectedStatusCode int) error {
+	if respStatusCode != expectedStatusCode {
+		return fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", expectedStatusCode, respStatusCode)
+	}
+	return nil
+}
+
+// getObjectTaggingVerify - verify the GetObjectTagging response contains the expected tag set.
+func getObjectTaggingVerify(res *http.Response, expectedStatusCode int, expectedTags []tag) error {
+	if err := verifyStatusObjectTagging(res.StatusCode, expectedStatusCode); err != nil {
+		return err
+	}
+	if expectedStatusCode != http.StatusOK {
+		return nil
+	}
+	gotTagSet := tagSet{}
+	if err := xmlDecoder(res.Body, &gotTagSet); err != nil {
+		return err
+	}
+	if len(gotTagSet.Tags) != len(expectedTags) {
+		return fmt.Errorf("Unexpected Number Of Tags: wanted %v, got %v", len(expectedTags), len(gotTagSet.Tags))
+	}
+	for i, tg := range gotTagSet.Tags {
+		if tg.Key != expectedTags[i].Key || tg.Value != expectedTags[i].Value {
+			return fmt.Errorf("Unexpected Tag: wanted %v=%v, got %v=%v", expectedTags[i].Key, expectedTags[i].Value, tg.Key, tg.Value)
+		}
+	}
+	return nil
+}
+
+// mainPutObjectTagging - Entry point for the Put/Get/DeleteObjectTagging API tests.
+func mainPutObjectTagging(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] ObjectTagging (Put/Get/Delete):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	objectName := s3verifyObjects[0].Key
+	// Include an empty tag value: several vendors reject these even though
+	// AWS accepts them, so it is a real interop pain point worth covering.
+	tags := []tag{
+		{Key: "project", Value: "s3verify"},
+		{Key: "optional", Value: ""},
+	}
+
+	putReq, err := newPutObjectTaggingReq(config, bucketName, objectName, tags)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putRes, err := config.execRequest("PUT", putReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(putRes)
+	if err := verifyStatusObjectTagging(putRes.StatusCode, http.StatusOK); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	getReq, err := newGetObjectTaggingReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRes, err := config.execRequest("GET", getReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(getRes)
+	if err := getObjectTaggingVerify(getRes, http.StatusOK, tags); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	delReq, err := newDeleteObjectTaggingReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	delRes, err := config.execRequest("DELETE", delReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(delRes)
+	if err := verifyStatusObjectTagging(delRes.StatusCode, http.StatusNoContent); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
@@ -0,0 +1,80 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"sync"
+)
+
+// runTestsWithWorkerPool runs tests (already filtered by the caller) against
+// config, dispatching non-critical tests to a bounded worker pool of size
+// parallel while treating every Critical test as a synchronization barrier:
+// all tests queued ahead of it are drained before it runs, and it always
+// runs by itself. extendedEnabled gates APItest.Extended entries exactly
+// like the serial runner in callAllAPIs. testCount starts the [NN/TOTAL]
+// counter and is returned so callers can keep counting across batches.
+func runTestsWithWorkerPool(config ServerConfig, tests []APItest, filter testFilter, extendedEnabled bool, parallel int, testCount int) int {
+	if parallel < 1 {
+		parallel = 1
+	}
+	var pending []APItest
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		sem := make(chan struct{}, parallel)
+		var wg sync.WaitGroup
+		for _, test := range pending {
+			test := test
+			count := testCount
+			testCount++
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				runAndRecord(config, test, count)
+			}()
+		}
+		wg.Wait()
+		pending = nil
+	}
+	for _, test := range tests {
+		api, tags := tagsForTest(test, testFuncName(test))
+		if !filter.allows(test, api, tags) {
+			continue
+		}
+		if test.Extended && !extendedEnabled {
+			continue
+		}
+		if test.Critical {
+			// Drain everything queued so far before the barrier runs.
+			flush()
+			if !runAndRecord(config, test, testCount) && !globalContinueOnCritical {
+				testCount++
+				writeReport()
+				os.Exit(1)
+			}
+			testCount++
+			continue
+		}
+		pending = append(pending, test)
+	}
+	flush()
+	return testCount
+}
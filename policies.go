@@ -38,9 +38,9 @@ type Statement struct {
 	Sid        string
 	Effect     string
 	Principal  User
-	Actions    []string                     `json:"Principal"`
-	Resources  set.StringSet                `json:"Action"`
-	Conditions map[string]map[string]string `json:"Condition,omnitempty"`
+	Actions    []string                     `json:"Action"`
+	Resources  set.StringSet                `json:"Resource"`
+	Conditions map[string]map[string]string `json:"Condition,omitempty"`
 }
 
 // BucketAccessPolicy - created bucket policy.
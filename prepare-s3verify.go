@@ -18,16 +18,69 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"net/http"
 	"net/url"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/minio/minio-go"
 )
 
+// numPrepareObjects/numPreparePartUploads are the object and multipart-part
+// counts prepareObjects/prepareMultipartParts stage ahead of a test run.
+const (
+	numPrepareObjects     = 1001
+	numPreparePartUploads = 1001
+)
+
+// barFeeder serializes scanBar updates coming from concurrent workers onto a
+// single owning goroutine, since scanBar itself is not safe for concurrent
+// use.
+type barFeeder struct {
+	tick chan struct{}
+	done chan struct{}
+}
+
+// newBarFeeder starts the owning goroutine and returns a feeder whose Tick
+// method can be called from any number of goroutines.
+func newBarFeeder(message string) *barFeeder {
+	f := &barFeeder{
+		tick: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	go func() {
+		for range f.tick {
+			scanBar(message)
+		}
+		close(f.done)
+	}()
+	return f
+}
+
+// Tick requests a single scanBar update. Safe for concurrent callers.
+func (f *barFeeder) Tick() {
+	select {
+	case f.tick <- struct{}{}:
+	default:
+		// A tick is already pending; drop this one rather than block callers.
+	}
+}
+
+// Stop shuts down the owning goroutine and waits for it to exit.
+func (f *barFeeder) Stop() {
+	close(f.tick)
+	<-f.done
+}
+
 // prepareBuckets - Uses minio-go library to create new testing buckets for use by s3verify.
 func prepareBuckets(region string, client *minio.Client) ([]string, error) {
 	message := "Creating test buckets"
@@ -51,29 +104,313 @@ func prepareBuckets(region string, client *minio.Client) ([]string, error) {
 	return bucketNames, nil
 }
 
-// TODO: see if parallelization has a place here.
-
-// prepareObjects - Uses minio-go library to create 1001 new testing objects for use by s3verify.
+// prepareObjects - Uses minio-go library to create 1001 new testing objects
+// for use by s3verify. PUTs are fanned out across a bounded worker pool
+// (sized by globalPrepareConcurrency); the first failure cancels all
+// in-flight and not-yet-started uploads.
 func prepareObjects(client *minio.Client, bucketName string) error {
 	message := "Creating test objects"
-	// TODO: update this to 1001...for testing purposes it is OK to leave it at 101 for now.
-	// Upload 1001 objects specifically for the list-objects tests.
-	for i := 0; i < 101; i++ {
-		// Spin scanBar
-		scanBar(message)
-		randomData := randString(60, rand.NewSource(time.Now().UnixNano()), "")
-		objectKey := "s3verify-object-" + strconv.Itoa(i)
-		// Create 60 bytes worth of random data for each object.
-		reader := bytes.NewReader([]byte(randomData))
-		_, err := client.PutObject(bucketName, objectKey, reader, "application/octet-stream")
+	bar := newBarFeeder(message)
+	defer bar.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sem := make(chan struct{}, globalPrepareConcurrency)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+
+	for i := 0; i < numPrepareObjects; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				return
+			}
+			bar.Tick()
+			randomData := randString(60, rand.NewSource(time.Now().UnixNano()), "")
+			objectKey := "s3verify-object-" + strconv.Itoa(i)
+			// Create 60 bytes worth of random data for each object.
+			reader := bytes.NewReader([]byte(randomData))
+			if _, err := client.PutObject(bucketName, objectKey, reader, "application/octet-stream"); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			bar.Tick()
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		printMessage(message, firstErr)
+		return firstErr
+	}
+	// Object preparation passed.
+	printMessage(message, nil)
+	return nil
+}
+
+// multipartResumeStateFile is where prepareMultipartParts checkpoints its
+// progress so an interrupted run can resume instead of restarting the whole
+// 1001-part upload from scratch.
+const multipartResumeStateFile = "s3verify-multipart-state.json"
+
+// multipartResumeState is the on-disk checkpoint written by
+// prepareMultipartParts after every part succeeds. Parts maps a part number
+// (as a string, since encoding/json requires string object keys) to its
+// ETag.
+type multipartResumeState struct {
+	Bucket   string            `json:"bucket"`
+	Key      string            `json:"key"`
+	UploadID string            `json:"uploadID"`
+	Parts    map[string]string `json:"parts"`
+}
+
+// loadMultipartResumeState reads multipartResumeStateFile, returning nil
+// (not an error) if no checkpoint is present.
+func loadMultipartResumeState() (*multipartResumeState, error) {
+	data, err := ioutil.ReadFile(multipartResumeStateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	state := &multipartResumeState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// save persists the checkpoint. Called with stateMu held by the caller.
+func (s *multipartResumeState) save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(multipartResumeStateFile, data, 0644)
+}
+
+// uploadPartBackoff bounds the retry-with-exponential-backoff loop
+// uploadPartWithRetry runs against transient per-part failures.
+const uploadPartBackoff = 200 * time.Millisecond
+
+// maxUploadPartAttempts is how many times uploadPartWithRetry tries a single
+// part before giving up and failing the whole prepare run.
+const maxUploadPartAttempts = 4
+
+// uploadPartWithRetry uploads a single part, retrying transient 5xx and
+// request-construction/network failures with exponential backoff. A 4xx
+// response is not transient and is returned immediately.
+func uploadPartWithRetry(config ServerConfig, bucketName, objectKey, uploadID string, partNumber int, partData []byte) (string, error) {
+	var lastErr error
+	for attempt := 0; attempt < maxUploadPartAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(uploadPartBackoff * time.Duration(1<<uint(attempt-1)))
+		}
+		req, err := newUploadPartReq(config, bucketName, objectKey, uploadID, partNumber, partData)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		res, err := execRequest(req, config.Client, bucketName, objectKey)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if res.StatusCode >= 500 {
+			lastErr = fmt.Errorf("Unexpected Response Status Code: wanted 200 OK, got %v", res.Status)
+			closeResponse(res)
+			continue
+		}
+		err = uploadPartVerify(res, "200 OK")
+		closeResponse(res)
+		if err != nil {
+			return "", err
+		}
+		etag := strings.TrimPrefix(res.Header.Get("ETag"), "\"")
+		etag = strings.TrimSuffix(etag, "\"")
+		return etag, nil
+	}
+	return "", lastErr
+}
+
+// listUploadedParts fetches the parts S3 already has recorded for uploadID,
+// used to resume an interrupted prepareMultipartParts run without
+// re-uploading parts that already succeeded.
+func listUploadedParts(config ServerConfig, bucketName, objectKey, uploadID string) (map[int]string, error) {
+	req, err := newListPartsReq(config, bucketName, objectKey, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	res, err := execRequest(req, config.Client)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponse(res)
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unexpected Response Status Code: wanted 200 OK, got %v", res.Status)
+	}
+	result := listObjectPartsResult{}
+	if err := xmlDecoder(res.Body, &result); err != nil {
+		return nil, err
+	}
+	uploaded := make(map[int]string, len(result.ObjectParts))
+	for _, part := range result.ObjectParts {
+		uploaded[part.PartNumber] = strings.Trim(part.ETag, "\"")
+	}
+	return uploaded, nil
+}
+
+// prepareMultipartParts - Uploads numPreparePartUploads parts (each
+// globalPreparePartSize bytes) of a single multipart upload directly via
+// newUploadPartReq (not minio-go), fanned out across a bounded worker pool,
+// to stage multipart throughput fixtures ahead of a test run. Transient
+// per-part failures are retried with backoff by uploadPartWithRetry, and
+// progress is checkpointed to multipartResumeStateFile so a run interrupted
+// partway through resumes via ListParts instead of re-uploading parts that
+// already succeeded. objectParts is populated in PartNumber order so
+// complMultipartUploads (consumed by CompleteMultipartUpload/ListParts) is
+// built deterministically regardless of completion order.
+func prepareMultipartParts(config ServerConfig, bucketName string) error {
+	message := "Creating test multipart object parts"
+	bar := newBarFeeder(message)
+	defer bar.Stop()
+
+	object := multipartObjects[0]
+
+	// Resume a prior interrupted run against the same bucket/object if a
+	// checkpoint is on disk; otherwise start a fresh upload.
+	uploaded := map[int]string{}
+	state, err := loadMultipartResumeState()
+	if err != nil {
+		printMessage(message, err)
+		return err
+	}
+	var uploadID string
+	if state != nil && state.Bucket == bucketName && state.Key == object.Key {
+		uploadID = state.UploadID
+		uploaded, err = listUploadedParts(config, bucketName, object.Key, uploadID)
 		if err != nil {
 			printMessage(message, err)
 			return err
 		}
-		// Spin scanBar
-		scanBar(message)
+	} else {
+		initiateReq, err := newInitiateMultipartUploadReq(config, bucketName, object.Key)
+		if err != nil {
+			printMessage(message, err)
+			return err
+		}
+		initiateRes, err := config.execRequest("POST", initiateReq)
+		if err != nil {
+			printMessage(message, err)
+			return err
+		}
+		uploadID, err = initiateMultipartUploadVerify(initiateRes, http.StatusOK)
+		closeResponse(initiateRes)
+		if err != nil {
+			printMessage(message, err)
+			return err
+		}
+		state = &multipartResumeState{Bucket: bucketName, Key: object.Key, UploadID: uploadID, Parts: map[string]string{}}
+		if err := state.save(); err != nil {
+			printMessage(message, err)
+			return err
+		}
 	}
-	// Object preparation passed.
+	object.UploadID = uploadID
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sem := make(chan struct{}, globalPrepareConcurrency)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	var stateMu sync.Mutex
+	partCh := make(chan objectPart, numPreparePartUploads)
+
+	for i := 1; i <= numPreparePartUploads; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		if etag, ok := uploaded[i]; ok {
+			// Already uploaded in a prior, interrupted run.
+			partCh <- objectPart{PartNumber: i, Size: int64(globalPreparePartSize), ETag: etag}
+			bar.Tick()
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(partNumber int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				return
+			}
+			bar.Tick()
+			partData := []byte(randString(globalPreparePartSize, rand.NewSource(time.Now().UnixNano()), ""))
+			etag, err := uploadPartWithRetry(config, bucketName, object.Key, uploadID, partNumber, partData)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			stateMu.Lock()
+			state.Parts[strconv.Itoa(partNumber)] = etag
+			saveErr := state.save()
+			stateMu.Unlock()
+			if saveErr != nil {
+				errOnce.Do(func() {
+					firstErr = saveErr
+					cancel()
+				})
+				return
+			}
+			partCh <- objectPart{
+				PartNumber: partNumber,
+				Size:       int64(len(partData)),
+				ETag:       etag,
+			}
+			bar.Tick()
+		}(i)
+	}
+	wg.Wait()
+	close(partCh)
+	if firstErr != nil {
+		printMessage(message, firstErr)
+		return firstErr
+	}
+
+	newParts := make([]objectPart, 0, numPreparePartUploads)
+	for part := range partCh {
+		newParts = append(newParts, part)
+	}
+	sort.Slice(newParts, func(i, j int) bool { return newParts[i].PartNumber < newParts[j].PartNumber })
+	objectParts = append(objectParts, newParts...)
+
+	complMultipartUploads[0].Parts = complMultipartUploads[0].Parts[:0]
+	for _, part := range newParts {
+		complMultipartUploads[0].Parts = append(complMultipartUploads[0].Parts, completePart{
+			PartNumber: part.PartNumber,
+			ETag:       part.ETag,
+		})
+	}
+
+	// All parts accounted for; drop the checkpoint so a future run starts
+	// fresh instead of mistakenly resuming a completed upload.
+	os.Remove(multipartResumeStateFile)
+
+	// Multipart part preparation passed.
 	printMessage(message, nil)
 	return nil
 }
@@ -113,9 +450,8 @@ func validateBucket(config ServerConfig, bucketName string) error {
 	return nil
 }
 
-// TODO: Create function using minio-go to upload 1001 parts of a multipart operation.
-
-// mainPrepareS3Verify - Create two new buckets and 1001 objects for s3verify to use in the test.
+// mainPrepareS3Verify - Create two new buckets, 1001 objects, and a
+// 1001-part multipart upload for s3verify to use in the test.
 func mainPrepareS3Verify(config ServerConfig) ([]string, error) {
 	// Extract necessary values from the config.
 	hostURL, err := url.Parse(config.Endpoint)
@@ -137,5 +473,9 @@ func mainPrepareS3Verify(config ServerConfig) ([]string, error) {
 	if err := prepareObjects(client, validBucketNames[0]); err != nil {
 		return nil, err
 	}
+	// Stage a 1001-part multipart upload in the same bucket.
+	if err := prepareMultipartParts(config, validBucketNames[0]); err != nil {
+		return nil, err
+	}
 	return validBucketNames, nil
 }
@@ -26,23 +26,22 @@ import (
 	"net/url"
 	"reflect"
 	"strconv"
-
-	"github.com/minio/s3verify/signv4"
 )
 
-var PutObjectCopyIfMatchReq = &http.Request{
-	Header: map[string][]string{
-	// X-Amz-Content-Sha256 will be set dynamically.
-	// Content-MD5 will be set dynamically.
-	// Content-Length will be set dynamically.
-	// x-amz-copy-source will be set dynamically.
-	// x-amz-copy-source-if-match will be set dynamically.
-	},
-	Method: "PUT",
-}
-
-// NewPutObjectCopyIfMatchReq - Create a new HTTP request for a PUT copy object.
+// NewPutObjectCopyIfMatchReq - Create a new HTTP request for a PUT copy
+// object. Allocated fresh per call so concurrent callers (e.g. the
+// --parallel worker pool) never share or race on the same *http.Request.
 func NewPutObjectCopyIfMatchReq(config ServerConfig, sourceBucketName, sourceObjectName, destBucketName, destObjectName, ETag string, objectData []byte) (*http.Request, error) {
+	PutObjectCopyIfMatchReq := &http.Request{
+		Header: map[string][]string{
+			// X-Amz-Content-Sha256 will be set dynamically.
+			// Content-MD5 will be set dynamically.
+			// Content-Length will be set dynamically.
+			// x-amz-copy-source will be set dynamically.
+			// x-amz-copy-source-if-match will be set dynamically.
+		},
+		Method: "PUT",
+	}
 	targetURL, err := makeTargetURL(config.Endpoint, destBucketName, destObjectName, config.Region)
 	if err != nil {
 		return nil, err
@@ -61,7 +60,7 @@ func NewPutObjectCopyIfMatchReq(config ServerConfig, sourceBucketName, sourceObj
 	PutObjectCopyIfMatchReq.Header.Set("x-amz-copy-source", url.QueryEscape(sourceBucketName+"/"+sourceObjectName))
 	PutObjectCopyIfMatchReq.Header.Set("x-amz-copy-source-if-match", ETag)
 
-	PutObjectCopyIfMatchReq = signv4.SignV4(*PutObjectCopyIfMatchReq, config.Access, config.Secret, config.Region)
+	PutObjectCopyIfMatchReq = config.Sign(PutObjectCopyIfMatchReq)
 	return PutObjectCopyIfMatchReq, nil
 }
 
@@ -22,21 +22,21 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-
-	"github.com/minio/s3verify/signv4"
 )
 
-var CopyObjectIfNoneMatchReq = &http.Request{
-	Header: map[string][]string{
-	// X-Amz-Content-Sha256 will be set dynamically.
-	// x-amz-copy-source will be set dynamically.
-	// x-amz-copy-source-if-match will be set dynamically.
-	},
-	Method: "PUT",
-}
-
-// NewPutObjectCopyIfNoneMatchReq - Create a new HTTP request for a CopyObject with the if-none-match header set.
+// NewPutObjectCopyIfNoneMatchReq - Create a new HTTP request for a CopyObject
+// with the if-none-match header set. Allocated fresh per call so concurrent
+// callers (e.g. the --parallel worker pool) never share or race on the same
+// *http.Request.
 func NewCopyObjectIfNoneMatchReq(config ServerConfig, sourceBucketName, sourceObjectName, destBucketName, destObjectName, ETag string, objectData []byte) (*http.Request, error) {
+	CopyObjectIfNoneMatchReq := &http.Request{
+		Header: map[string][]string{
+			// X-Amz-Content-Sha256 will be set dynamically.
+			// x-amz-copy-source will be set dynamically.
+			// x-amz-copy-source-if-match will be set dynamically.
+		},
+		Method: "PUT",
+	}
 	targetURL, err := makeTargetURL(config.Endpoint, destBucketName, destObjectName, config.Region)
 	if err != nil {
 		return nil, err
@@ -53,7 +53,7 @@ func NewCopyObjectIfNoneMatchReq(config ServerConfig, sourceBucketName, sourceOb
 	CopyObjectIfNoneMatchReq.Header.Set("x-amz-copy-source", url.QueryEscape(sourceBucketName+"/"+sourceObjectName))
 	CopyObjectIfNoneMatchReq.Header.Set("x-amz-copy-source-if-none-match", ETag)
 
-	CopyObjectIfNoneMatchReq = signv4.SignV4(*CopyObjectIfNoneMatchReq, config.Access, config.Secret, config.Region)
+	CopyObjectIfNoneMatchReq = config.Sign(CopyObjectIfNoneMatchReq)
 	return CopyObjectIfNoneMatchReq, nil
 }
 
@@ -24,24 +24,23 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-
-	"github.com/minio/s3verify/signv4"
 )
 
-var PutObjectCopyReq = &http.Request{
-	Header: map[string][]string{
-	// X-Amz-Content-Sha256 will be set dynamically.
-	// Content-MD5 will be set dynamically.
-	// Content-Length will be set dynamically.
-	// x-amz-copy-source will be set dynamically.
-	},
-	// Body will be set dynamically.
-	// Body:
-	Method: "PUT",
-}
-
 // NewPutObjectCopyReq - Create a new HTTP request for PUT object with copy-
+// source set. Allocated fresh per call so concurrent callers (e.g. the
+// --parallel worker pool) never share or race on the same *http.Request.
 func NewPutObjectCopyReq(config ServerConfig, sourceBucketName, sourceObjectName, destBucketName, destObjectName string, objectData []byte) (*http.Request, error) {
+	PutObjectCopyReq := &http.Request{
+		Header: map[string][]string{
+			// X-Amz-Content-Sha256 will be set dynamically.
+			// Content-MD5 will be set dynamically.
+			// Content-Length will be set dynamically.
+			// x-amz-copy-source will be set dynamically.
+		},
+		// Body will be set dynamically.
+		// Body:
+		Method: "PUT",
+	}
 	targetURL, err := makeTargetURL(config.Endpoint, destBucketName, destObjectName, config.Region)
 	if err != nil {
 		return nil, err
@@ -60,12 +59,11 @@ func NewPutObjectCopyReq(config ServerConfig, sourceBucketName, sourceObjectName
 	PutObjectCopyReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
 	PutObjectCopyReq.Header.Set("x-amz-copy-source", url.QueryEscape(sourceBucketName+"/"+sourceObjectName))
 
-	PutObjectCopyReq = signv4.SignV4(*PutObjectCopyReq, config.Access, config.Secret, config.Region)
+	PutObjectCopyReq = config.Sign(PutObjectCopyReq)
 
 	return PutObjectCopyReq, nil
 }
 
-//
 func PutObjectCopyVerify(res *http.Response, expectedStatus string) error {
 	if err := VerifyHeaderPutObjectCopy(res); err != nil {
 		return err
@@ -19,15 +19,12 @@ package main
 import (
 	"bytes"
 	"encoding/base64"
-	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"strconv"
 	"time"
-
-	"github.com/minio/s3verify/signv4"
 )
 
 // Store all objects that are uploaded through standard PUT operations.
@@ -56,18 +53,26 @@ func newPutObjectReq(config ServerConfig, bucketName, objectName string, objectD
 	// Fill request headers and URL.
 	putObjectReq.URL = targetURL
 
-	// Compute md5Sum and sha256Sum from the input data.
+	// Compute md5Sum and sha256Sum from the input data, skipping the hash
+	// entirely in favor of UNSIGNED-PAYLOAD once objectData crosses
+	// unsignedPayloadThreshold so multi-GB upload tests don't pay for a
+	// full MD5+SHA256 pass over data already held in memory.
 	reader := bytes.NewReader(objectData)
-	md5Sum, sha256Sum, contentLength, err := computeHash(reader)
+	md5Sum, _, contentLength, payloadSha256Header, err := computeHashOrUnsigned(reader)
 	if err != nil {
 		return nil, err
 	}
-	putObjectReq.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum))
-	putObjectReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	if md5Sum != nil {
+		putObjectReq.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum))
+	}
+	putObjectReq.Header.Set("X-Amz-Content-Sha256", payloadSha256Header)
 	putObjectReq.ContentLength = contentLength
-	// Set the body to the data held in objectData.
-	putObjectReq.Body = ioutil.NopCloser(reader)
-	putObjectReq = signv4.SignV4(*putObjectReq, config.Access, config.Secret, config.Region)
+	// Set the body to the data held in objectData. reader is a *bytes.Reader,
+	// genuinely seekable, so wrap it in readSeekCloser (not
+	// ioutil.NopCloser, which would strip Seek) to let execRequest retry by
+	// seeking back to the start instead of buffering objectData again.
+	putObjectReq.Body = readSeekCloser{reader}
+	putObjectReq = config.Sign(putObjectReq)
 	return putObjectReq, nil
 }
 
@@ -26,6 +26,97 @@ import (
 	"github.com/minio/s3verify/signv4"
 )
 
+// mainRemoveBucketNotEmpty - Entry point for verifying that RemoveBucket is
+// rejected with 409 BucketNotEmpty while the bucket still holds an object,
+// and succeeds once that object is cleared out. Neither mainRemoveBucketExists
+// nor mainRemoveBucketDNE covers this precondition.
+func mainRemoveBucketNotEmpty(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] RemoveBucket (Bucket Not Empty):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := randString(60, rand.NewSource(time.Now().UnixNano()), "s3verify")
+
+	putBucketReq, err := newPutBucketReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putBucketRes, err := config.execRequest("PUT", putBucketReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putBucketRes)
+	scanBar(message)
+
+	objectName := "s3verify-remove-bucket-not-empty"
+	putObjectReq, err := newPutObjectReq(config, bucketName, objectName, []byte("s3verify-remove-bucket-not-empty-payload"))
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	putObjectRes, err := execRequest(putObjectReq, config.Client)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(putObjectRes)
+	scanBar(message)
+
+	// The bucket still holds an object: RemoveBucket must be rejected.
+	removeReq, err := newRemoveBucketReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	removeRes, err := execRequest(removeReq, config.Client)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(removeRes, http.StatusConflict, "BucketNotEmpty")
+	closeResponse(removeRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	// Clear the bucket out, then the identical DELETE must succeed.
+	removeObjectReq, err := newRemoveObjectReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	removeObjectRes, err := execRequest(removeObjectReq, config.Client)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(removeObjectRes)
+	scanBar(message)
+
+	retryReq, err := newRemoveBucketReq(config, bucketName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	retryRes, err := execRequest(retryReq, config.Client)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = removeBucketVerify(retryRes, "204 No Content", ErrorResponse{})
+	closeResponse(retryRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
+
 // newRemoveBucketReq - Fill in the dynamic fields of a DELETE request here.
 func newRemoveBucketReq(config ServerConfig, bucketName string) (*http.Request, error) {
 	// removeBucketReq is a new DELETE bucket request.
@@ -45,7 +136,7 @@ func newRemoveBucketReq(config ServerConfig, bucketName string) (*http.Request,
 	}
 	removeBucketReq.URL = targetURL
 	// Sign the necessary headers.
-	removeBucketReq = signv4.SignV4(*removeBucketReq, config.Access, config.Secret, config.Region)
+	removeBucketReq = config.Sign(removeBucketReq)
 	return removeBucketReq, nil
 }
 
@@ -41,7 +41,7 @@ func newRemoveObjectReq(config ServerConfig, bucketName, objectName string) (*ht
 		return nil, err
 	}
 	removeObjectReq.URL = targetURL
-	removeObjectReq = signv4.SignV4(*removeObjectReq, config.Access, config.Secret, config.Region)
+	removeObjectReq = config.Sign(removeObjectReq)
 	return removeObjectReq, nil
 }
 
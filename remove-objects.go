@@ -0,0 +1,287 @@
+/*
+ * Minio S3verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// maxRemoveObjectsBatch - S3 rejects a bulk-delete request carrying more
+// than 1000 <Object> entries.
+const maxRemoveObjectsBatch = 1000
+
+// deleteObject/deleteRequest model the XML body of a BulkDelete
+// (POST /?delete) request.
+type deleteObject struct {
+	Key string `xml:"Key"`
+}
+
+type deleteRequest struct {
+	XMLName xml.Name       `xml:"Delete"`
+	Quiet   bool           `xml:"Quiet,omitempty"`
+	Objects []deleteObject `xml:"Object"`
+}
+
+// deletedObject/deleteError/deleteResult model the <DeleteResult> body
+// returned by a BulkDelete request.
+type deletedObject struct {
+	Key string `xml:"Key"`
+}
+
+type deleteError struct {
+	Key     string `xml:"Key"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type deleteResult struct {
+	XMLName xml.Name        `xml:"DeleteResult"`
+	Deleted []deletedObject `xml:"Deleted"`
+	Errors  []deleteError   `xml:"Error"`
+}
+
+// newRemoveObjectsReq - Create a new HTTP request for the BulkDelete
+// (POST /?delete) API. keys is intentionally not capped at
+// maxRemoveObjectsBatch here so that callers can also build the oversized
+// request used to verify the server-side rejection of batches over 1000
+// keys.
+func newRemoveObjectsReq(config ServerConfig, bucketName string, keys []string, quiet bool) (Request, error) {
+	var removeObjectsReq = Request{
+		customHeader: http.Header{},
+	}
+
+	removeObjectsReq.bucketName = bucketName
+	removeObjectsReq.queryValues = url.Values{"delete": []string{""}}
+
+	objects := make([]deleteObject, len(keys))
+	for i, key := range keys {
+		objects[i] = deleteObject{Key: key}
+	}
+	deleteBytes, err := xml.Marshal(deleteRequest{Quiet: quiet, Objects: objects})
+	if err != nil {
+		return Request{}, err
+	}
+	reader := bytes.NewReader(deleteBytes)
+	_, sha256Sum, contentLength, err := computeHash(reader)
+	if err != nil {
+		return Request{}, err
+	}
+	reader.Seek(0, 0)
+	sum := md5.Sum(deleteBytes)
+	removeObjectsReq.contentBody = reader
+	removeObjectsReq.contentLength = contentLength
+	removeObjectsReq.customHeader.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	removeObjectsReq.customHeader.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
+	removeObjectsReq.customHeader.Set("User-Agent", appUserAgent)
+
+	return removeObjectsReq, nil
+}
+
+// removeObjectsVerify - Verify that the response returned matches what is
+// expected.
+func removeObjectsVerify(res *http.Response, expectedStatusCode int) (deleteResult, error) {
+	result := deleteResult{}
+	if err := verifyStatusRemoveObjects(res.StatusCode, expectedStatusCode); err != nil {
+		return result, err
+	}
+	if err := verifyHeaderRemoveObjects(res.Header); err != nil {
+		return result, err
+	}
+	if err := xmlDecoder(res.Body, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// verifyStatusRemoveObjects - verify the status returned matches what is expected.
+func verifyStatusRemoveObjects(respStatusCode, expectedStatusCode int) error {
+	if respStatusCode != expectedStatusCode {
+		return fmt.Errorf("Unexpected Response Status Code: wanted %v, got %v", expectedStatusCode, respStatusCode)
+	}
+	return nil
+}
+
+// verifyHeaderRemoveObjects - verify the header returned matches what is expected.
+func verifyHeaderRemoveObjects(header http.Header) error {
+	if err := verifyStandardHeaders(header); err != nil {
+		return err
+	}
+	return nil
+}
+
+// mainRemoveObjects - Entry point for the BulkDelete API test. Uploads a
+// handful of throwaway objects, removes them in one bulk-delete call with
+// Quiet disabled (every key must come back in <Deleted>), repeats with
+// Quiet enabled (no <Deleted> entries should be echoed back), proves a batch
+// mixing an uploaded key with never-uploaded keys still deletes cleanly with
+// no <Error> entries, and finally proves a batch over maxRemoveObjectsBatch
+// keys is rejected.
+func mainRemoveObjects(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] BulkDelete (RemoveObjects):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+
+	verboseKeys := []string{
+		"s3verify-bulk-delete-verbose-1",
+		"s3verify-bulk-delete-verbose-2",
+		"s3verify-bulk-delete-verbose-3",
+	}
+	quietKeys := []string{
+		"s3verify-bulk-delete-quiet-1",
+		"s3verify-bulk-delete-quiet-2",
+	}
+	for _, key := range append(append([]string{}, verboseKeys...), quietKeys...) {
+		putReq, err := newPutObjectReq(config, bucketName, key, []byte("s3verify-bulk-delete-payload"))
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		putRes, err := execRequest(putReq, config.Client, bucketName, key)
+		if err != nil {
+			printMessage(message, err)
+			return false
+		}
+		closeResponse(putRes)
+		scanBar(message)
+	}
+
+	// Quiet disabled: every removed key must be echoed back in <Deleted>.
+	verboseReq, err := newRemoveObjectsReq(config, bucketName, verboseKeys, false)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	verboseRes, err := config.execRequest("POST", verboseReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	verboseResult, err := removeObjectsVerify(verboseRes, http.StatusOK)
+	closeResponse(verboseRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	if len(verboseResult.Deleted) != len(verboseKeys) {
+		printMessage(message, fmt.Errorf("Unexpected Deleted count: wanted %v, got %v", len(verboseKeys), len(verboseResult.Deleted)))
+		return false
+	}
+	scanBar(message)
+
+	// Quiet enabled: successful deletes must be suppressed from the result.
+	quietReq, err := newRemoveObjectsReq(config, bucketName, quietKeys, true)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	quietRes, err := config.execRequest("POST", quietReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	quietResult, err := removeObjectsVerify(quietRes, http.StatusOK)
+	closeResponse(quietRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	if len(quietResult.Deleted) != 0 {
+		printMessage(message, fmt.Errorf("Unexpected Deleted entries with Quiet enabled: got %v", quietResult.Deleted))
+		return false
+	}
+	scanBar(message)
+
+	// A batch mixing an uploaded key with keys that were never uploaded: S3
+	// treats BulkDelete as idempotent, so every key -- present or not --
+	// must still come back in <Deleted> with no <Error> entries.
+	mixedKeys := []string{
+		"s3verify-bulk-delete-mixed-1",
+		"s3verify-bulk-delete-mixed-missing-1",
+		"s3verify-bulk-delete-mixed-missing-2",
+	}
+	mixedPutReq, err := newPutObjectReq(config, bucketName, mixedKeys[0], []byte("s3verify-bulk-delete-payload"))
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	mixedPutRes, err := execRequest(mixedPutReq, config.Client, bucketName, mixedKeys[0])
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(mixedPutRes)
+	scanBar(message)
+
+	mixedReq, err := newRemoveObjectsReq(config, bucketName, mixedKeys, false)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	mixedRes, err := config.execRequest("POST", mixedReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	mixedResult, err := removeObjectsVerify(mixedRes, http.StatusOK)
+	closeResponse(mixedRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	if len(mixedResult.Errors) != 0 {
+		printMessage(message, fmt.Errorf("Unexpected Error entries for a mixed batch: got %v", mixedResult.Errors))
+		return false
+	}
+	if len(mixedResult.Deleted) != len(mixedKeys) {
+		printMessage(message, fmt.Errorf("Unexpected Deleted count: wanted %v, got %v", len(mixedKeys), len(mixedResult.Deleted)))
+		return false
+	}
+	scanBar(message)
+
+	// A batch over maxRemoveObjectsBatch keys must be rejected outright.
+	oversizedKeys := make([]string, maxRemoveObjectsBatch+1)
+	for i := range oversizedKeys {
+		oversizedKeys[i] = fmt.Sprintf("s3verify-bulk-delete-oversized-%d", i)
+	}
+	oversizedReq, err := newRemoveObjectsReq(config, bucketName, oversizedKeys, false)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	oversizedRes, err := config.execRequest("POST", oversizedReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	err = verifyErrorCode(oversizedRes, http.StatusBadRequest, "MalformedXML")
+	closeResponse(oversizedRes)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
@@ -0,0 +1,222 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package report collects structured results for every s3verify API test
+// and renders them in machine readable formats so that results can be
+// diffed across S3 compatible backends or consumed by CI systems.
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Status describes the outcome of a single API test.
+type Status string
+
+// All statuses a test can finish in.
+const (
+	StatusPass Status = "PASS"
+	StatusFail Status = "FAIL"
+	StatusSkip Status = "SKIP"
+)
+
+// Format is a supported reporter output format.
+type Format string
+
+// Supported output formats.
+const (
+	FormatJSON     Format = "json"
+	FormatJUnit    Format = "junit"
+	FormatMarkdown Format = "markdown"
+	FormatTAP      Format = "tap"
+)
+
+// Record is a single structured result emitted by the APItest runner.
+type Record struct {
+	Name       string        `json:"name"`
+	API        string        `json:"api"`
+	Endpoint   string        `json:"endpoint"`
+	Region     string        `json:"region"`
+	Extended   bool          `json:"extended"`
+	Critical   bool          `json:"critical"`
+	Status     Status        `json:"status"`
+	Duration   time.Duration `json:"durationNanos"`
+	HTTPStatus int           `json:"httpStatus,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	// CanonicalRequest, when set, is the signed request s3verify sent for a
+	// failing test, captured at the point of comparison for debugging.
+	CanonicalRequest string `json:"canonicalRequest,omitempty"`
+	// RequestID/HostID are the x-amz-request-id/x-amz-id-2 headers off the
+	// response, when the server set them, so a failure against real S3 can
+	// be filed with Amazon support by ID.
+	RequestID string `json:"requestId,omitempty"`
+	HostID    string `json:"hostId,omitempty"`
+}
+
+// Reporter accumulates Records and renders them in the requested Format.
+// Add may be called from multiple goroutines (e.g. --parallel test runs),
+// so Add appends to Records under mu; WriteTo reads Records without taking mu.
+type Reporter struct {
+	Format  Format
+	Records []Record
+	mu      sync.Mutex
+}
+
+// New creates a new Reporter for the given format. An unsupported format
+// falls back to FormatJSON so callers always get a usable reporter.
+func New(format string) *Reporter {
+	f := Format(format)
+	switch f {
+	case FormatJSON, FormatJUnit, FormatMarkdown, FormatTAP:
+	default:
+		f = FormatJSON
+	}
+	return &Reporter{Format: f}
+}
+
+// Add appends a completed test Record to the report. Safe for concurrent use.
+func (r *Reporter) Add(rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Records = append(r.Records, rec)
+}
+
+// WriteTo renders the accumulated Records to w using the Reporter's Format.
+func (r *Reporter) WriteTo(w io.Writer) error {
+	switch r.Format {
+	case FormatJUnit:
+		return r.writeJUnit(w)
+	case FormatMarkdown:
+		return r.writeMarkdown(w)
+	case FormatTAP:
+		return r.writeTAP(w)
+	default:
+		return r.writeJSON(w)
+	}
+}
+
+func (r *Reporter) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Records)
+}
+
+// junitTestsuite/junitTestcase mirror the subset of the JUnit XML schema
+// consumed by common CI systems (Jenkins, GitLab).
+type junitTestsuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestcase `xml:"testcase"`
+}
+
+type junitTestcase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitSkipped struct{}
+
+func (r *Reporter) writeJUnit(w io.Writer) error {
+	suite := junitTestsuite{Name: "s3verify"}
+	for _, rec := range r.Records {
+		suite.Tests++
+		tc := junitTestcase{
+			Name:      rec.Name,
+			ClassName: rec.API,
+			Time:      rec.Duration.Seconds(),
+		}
+		switch rec.Status {
+		case StatusFail:
+			suite.Failures++
+			failureMessage := rec.Error
+			if rec.RequestID != "" || rec.HostID != "" {
+				failureMessage = fmt.Sprintf("%s (x-amz-request-id: %s, x-amz-id-2: %s)", failureMessage, rec.RequestID, rec.HostID)
+			}
+			tc.Failure = &junitFailure{Message: failureMessage}
+		case StatusSkip:
+			suite.Skipped++
+			tc.Skipped = &junitSkipped{}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(suite)
+}
+
+// writeTAP renders the Test Anything Protocol format consumed by prove(1)
+// and most CI TAP parsers.
+func (r *Reporter) writeTAP(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "TAP version 13\n1..%d\n", len(r.Records)); err != nil {
+		return err
+	}
+	for i, rec := range r.Records {
+		switch rec.Status {
+		case StatusFail:
+			if _, err := fmt.Fprintf(w, "not ok %d - %s\n", i+1, rec.Name); err != nil {
+				return err
+			}
+			if rec.Error != "" {
+				if _, err := fmt.Fprintf(w, "  ---\n  message: %q\n  ...\n", rec.Error); err != nil {
+					return err
+				}
+			}
+		case StatusSkip:
+			if _, err := fmt.Fprintf(w, "ok %d - %s # SKIP\n", i+1, rec.Name); err != nil {
+				return err
+			}
+		default:
+			if _, err := fmt.Fprintf(w, "ok %d - %s\n", i+1, rec.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Reporter) writeMarkdown(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "| Test | API | Status | Duration | HTTP | Error | Request ID | Host ID |\n"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "|---|---|---|---|---|---|---|---|\n"); err != nil {
+		return err
+	}
+	for _, rec := range r.Records {
+		if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s | %d | %s | %s | %s |\n",
+			rec.Name, rec.API, rec.Status, rec.Duration, rec.HTTPStatus, rec.Error, rec.RequestID, rec.HostID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+)
+
+// defaultMaxRetries is how many attempts in total execRequestWithRetry makes
+// for a request when ServerConfig.MaxRetries is left at its zero value (i.e.
+// --max-retries was not passed).
+const defaultMaxRetries = 3
+
+// execRequestRetryBackoff bounds the exponential-backoff delay between
+// execRequestWithRetry attempts, before jitter is added.
+const execRequestRetryBackoff = 200 * time.Millisecond
+
+// execRequestWithRetry executes req via execRequest, retrying with
+// exponential backoff plus jitter, making at most config.MaxRetries attempts
+// in total (falling back to defaultMaxRetries when unset), on a 5xx response, a "RequestTimeout"
+// S3 error code, or a timing-out net.Error. It is meant for idempotent
+// DELETE/HEAD/GET requests such as AbortMultipartUpload, where retrying a
+// transient failure cannot duplicate server-side effects. req is reused
+// across attempts as is: its Body is not rewound between attempts, so a
+// request with a body is not resent intact on a retry.
+func execRequestWithRetry(req *http.Request, config ServerConfig, bucketName, objectName string) (*http.Response, error) {
+	maxRetries := config.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+	var lastErr error
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := execRequestRetryBackoff * time.Duration(int64(1)<<uint(attempt-1))
+			jitter := time.Duration(globalRandom.Int63n(int64(backoff)))
+			time.Sleep(backoff + jitter)
+		}
+		res, err := execRequest(req, config.Client, bucketName, objectName)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				lastErr = err
+				continue
+			}
+			return nil, err
+		}
+		if res.StatusCode >= 500 {
+			lastErr = fmt.Errorf("Unexpected Response Status Code: wanted < 500, got %v", res.Status)
+			closeResponse(res)
+			continue
+		}
+		if res.StatusCode == http.StatusBadRequest && isRequestTimeoutResponse(res) {
+			lastErr = fmt.Errorf("RequestTimeout: %v", res.Status)
+			closeResponse(res)
+			continue
+		}
+		return res, nil
+	}
+	return nil, lastErr
+}
+
+// isRequestTimeoutResponse peeks res.Body for a RequestTimeout S3 error
+// code without consuming it for the caller.
+func isRequestTimeoutResponse(res *http.Response) bool {
+	bodyBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return false
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+	errResponse := ErrorResponse{}
+	if xml.Unmarshal(bodyBytes, &errResponse) != nil {
+		return false
+	}
+	return errResponse.Code == "RequestTimeout"
+}
@@ -17,7 +17,11 @@
 package main
 
 import (
+	"fmt"
 	"net/http"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/httptracer"
@@ -29,21 +33,128 @@ type ServerConfig struct {
 	Endpoint string
 	Region   string
 	Client   *http.Client
+	// SignatureVersion selects which AWS signature scheme requests are
+	// signed with. Defaults to "v4"; "v2" is also supported to cover
+	// gateways that still speak the legacy signer.
+	SignatureVersion string
+	// Signer performs the actual request signing for SignatureVersion.
+	Signer Signer
+	// MaxRetries bounds how many attempts execRequestWithRetry makes before
+	// giving up on a transient failure. Left at 0 (i.e. --max-retries not
+	// passed), defaultMaxRetries is used instead.
+	MaxRetries int
+	// locationCache memoizes GetBucketLocation lookups made via getBucketLocation.
+	locationCache *bucketLocationCache
 }
 
 // newServerConfig - new server config.
 func newServerConfig(ctx *cli.Context) *ServerConfig {
 	// Set config fields from either flags or env. variables.
 	serverCfg := &ServerConfig{
-		Access:   ctx.String("access"),
-		Secret:   ctx.String("secret"),
-		Endpoint: ctx.String("url"),
-		Region:   ctx.String("region"),
-		Client:   &http.Client{},
+		Access:           ctx.String("access"),
+		Secret:           ctx.String("secret"),
+		Endpoint:         ctx.String("url"),
+		Region:           ctx.String("region"),
+		Client:           &http.Client{},
+		SignatureVersion: ctx.GlobalString("signature"),
+		MaxRetries:       ctx.GlobalInt("max-retries"),
+		locationCache:    newBucketLocationCache(),
 	}
+	if serverCfg.SignatureVersion == "" {
+		serverCfg.SignatureVersion = "v4"
+	}
+	serverCfg.Signer = newSigner(serverCfg.SignatureVersion)
 	if ctx.Bool("debug") || ctx.GlobalBool("debug") {
 		// Set up new tracer.
 		serverCfg.Client.Transport = httptracer.GetNewTraceTransport(newTraceV4(), http.DefaultTransport)
 	}
 	return serverCfg
 }
+
+// concurrencyStats aggregates the per-call latency and byte count
+// runConcurrent records across a worker pool, so callers can report
+// p50/p95/p99 latency, throughput, and error rate for a test without each
+// one hand-rolling the percentile math.
+type concurrencyStats struct {
+	Errors    int
+	Bytes     int64
+	Elapsed   time.Duration
+	latencies []time.Duration // Sorted ascending by Finish.
+}
+
+// Finish sorts the recorded latencies so percentile can binary-search them.
+// Must be called once, after every worker has reported in.
+func (s *concurrencyStats) Finish(elapsed time.Duration) {
+	s.Elapsed = elapsed
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+}
+
+// percentile returns the latency at the given percentile (0-100) of the
+// calls runConcurrent recorded. Finish must have been called first.
+func (s *concurrencyStats) percentile(p int) time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	idx := p * (len(s.latencies) - 1) / 100
+	return s.latencies[idx]
+}
+
+// String renders p50/p95/p99 latency, aggregate throughput, and error rate.
+func (s *concurrencyStats) String() string {
+	n := len(s.latencies)
+	var mbps float64
+	if s.Elapsed > 0 {
+		mbps = float64(s.Bytes) / s.Elapsed.Seconds() / (1024 * 1024)
+	}
+	var errRate float64
+	if n > 0 {
+		errRate = float64(s.Errors) / float64(n) * 100
+	}
+	return fmt.Sprintf("%d reqs, p50=%v p95=%v p99=%v, %.2f MB/s, %.1f%% errors",
+		n, s.percentile(50), s.percentile(95), s.percentile(99), mbps, errRate)
+}
+
+// runConcurrent fans work out across a worker pool bounded by concurrency
+// (1 runs the n calls serially), invoking work(i) for every i in [0,n).
+// Every call runs to completion regardless of earlier failures, so the
+// returned, index-aligned []error always has n entries (nil for a call
+// that succeeded); work reports the bytes it transferred so runConcurrent
+// can fold per-call wall time and bytes into the returned concurrencyStats.
+func runConcurrent(concurrency, n int, work func(i int) (bytes int64, err error)) ([]error, *concurrencyStats) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	errs := make([]error, n)
+	stats := &concurrencyStats{latencies: make([]time.Duration, n)}
+	var bytesTotal int64
+	var errCount int
+	var mu sync.Mutex
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			callStart := time.Now()
+			bytes, err := work(i)
+			stats.latencies[i] = time.Since(callStart)
+			if err != nil {
+				errs[i] = err
+				mu.Lock()
+				errCount++
+				mu.Unlock()
+			}
+			mu.Lock()
+			bytesTotal += bytes
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+	stats.Bytes = bytesTotal
+	stats.Errors = errCount
+	stats.Finish(time.Since(start))
+	return errs, stats
+}
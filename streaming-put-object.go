@@ -0,0 +1,308 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// streamingChunkSize is the payload size of every aws-chunked frame but the
+// last, matching the size the AWS CLI/SDKs default to for chunked uploads.
+const streamingChunkSize = 64 * 1024
+
+// emptySHA256Hex is hex(sha256("")): the STREAMING-AWS4-HMAC-SHA256-PAYLOAD
+// algorithm substitutes this fixed value for "hash of this chunk's trailing
+// headers" in every chunk's string-to-sign, since this tool never sends
+// chunk trailers.
+const emptySHA256Hex = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// deterministicReader streams reproducible pseudo-random bytes without ever
+// holding the full payload in memory: two readers created with the same
+// seed and size produce byte-identical output. mainPutObjectStreaming uses
+// it as the source of a >100MiB upload, hashing it on the fly so the payload
+// is not buffered on either the send or the verify side.
+type deterministicReader struct {
+	rng       *rand.Rand
+	remaining int64
+}
+
+func newDeterministicReader(seed int64, size int64) *deterministicReader {
+	return &deterministicReader{rng: rand.New(rand.NewSource(seed)), remaining: size}
+}
+
+func (r *deterministicReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.rng.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// deriveStreamingSigningKey computes the AWS SigV4 signing key for the
+// given date (YYYYMMDD), region, and service: the same HMAC chain
+// signv4.SignV4 performs internally to sign the seed request.
+func deriveStreamingSigningKey(secret, date, region, service string) []byte {
+	hmacSHA256 := func(key []byte, data string) []byte {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(data))
+		return mac.Sum(nil)
+	}
+	kDate := hmacSHA256([]byte("AWS4"+secret), date)
+	kRegion := hmacSHA256(kDate, region)
+	kService := hmacSHA256(kRegion, service)
+	return hmacSHA256(kService, "aws4_request")
+}
+
+// parseSigV4Signature extracts the Signature= value from an
+// AWS4-HMAC-SHA256 Authorization header: the seed signature the first
+// chunk's signature chains from.
+func parseSigV4Signature(authorization string) (string, error) {
+	const marker = "Signature="
+	idx := strings.Index(authorization, marker)
+	if idx < 0 {
+		return "", fmt.Errorf("Authorization header carries no Signature=: %v", authorization)
+	}
+	return authorization[idx+len(marker):], nil
+}
+
+// chunkSignature computes the signature of one aws-chunked frame, chaining
+// from the previous chunk's signature (or the seed signature, for the
+// first chunk) per the STREAMING-AWS4-HMAC-SHA256-PAYLOAD algorithm.
+func chunkSignature(signingKey []byte, timestamp, scope, priorSignature string, chunkData []byte) string {
+	chunkHash := sha256.Sum256(chunkData)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256-PAYLOAD",
+		timestamp,
+		scope,
+		priorSignature,
+		emptySHA256Hex,
+		hex.EncodeToString(chunkHash[:]),
+	}, "\n")
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(stringToSign))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// chunkFrameOverhead returns the byte length of one aws-chunked frame's
+// header and trailing CRLFs, excluding the chunk data itself: every
+// chunk-signature is a 64-character hex SHA-256, so the overhead is fixed
+// once chunkDataLen's hex representation is known.
+func chunkFrameOverhead(chunkDataLen int64) int64 {
+	header := strconv.FormatInt(chunkDataLen, 16) + ";chunk-signature=" + strings.Repeat("0", 64) + "\r\n"
+	return int64(len(header)) + int64(len("\r\n"))
+}
+
+// streamingChunkedLength returns the Content-Length of the aws-chunked body
+// that will wrap decodedLength bytes of payload, including the mandatory
+// zero-length terminating chunk.
+func streamingChunkedLength(decodedLength int64) int64 {
+	var total int64
+	remaining := decodedLength
+	for remaining > 0 {
+		n := int64(streamingChunkSize)
+		if remaining < n {
+			n = remaining
+		}
+		total += chunkFrameOverhead(n) + n
+		remaining -= n
+	}
+	total += chunkFrameOverhead(0)
+	return total
+}
+
+// streamingChunkedReader wraps a payload reader, emitting it as a sequence
+// of aws-chunked frames ("<hex-size>;chunk-signature=<sig>\r\n<data>\r\n"),
+// each chunk's signature chained from the previous one, ending in the
+// mandatory zero-length terminating chunk. It never buffers more than one
+// chunk of the underlying payload at a time.
+type streamingChunkedReader struct {
+	src            io.Reader
+	signingKey     []byte
+	timestamp      string
+	scope          string
+	priorSignature string
+	buf            bytes.Buffer
+	done           bool
+}
+
+func newStreamingChunkedReader(src io.Reader, signingKey []byte, timestamp, scope, seedSignature string) *streamingChunkedReader {
+	return &streamingChunkedReader{src: src, signingKey: signingKey, timestamp: timestamp, scope: scope, priorSignature: seedSignature}
+}
+
+func (r *streamingChunkedReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		chunk := make([]byte, streamingChunkSize)
+		n, err := io.ReadFull(r.src, chunk)
+		chunk = chunk[:n]
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+		r.priorSignature = chunkSignature(r.signingKey, r.timestamp, r.scope, r.priorSignature, chunk)
+		r.buf.WriteString(strconv.FormatInt(int64(n), 16) + ";chunk-signature=" + r.priorSignature + "\r\n")
+		r.buf.Write(chunk)
+		r.buf.WriteString("\r\n")
+		if n == 0 {
+			// The source is exhausted: the frame just written is the
+			// mandatory zero-length terminating chunk.
+			r.done = true
+		}
+	}
+	return r.buf.Read(p)
+}
+
+// newStreamingPutObjectReq - Create a PutObject request that signs its body
+// with STREAMING-AWS4-HMAC-SHA256-PAYLOAD instead of a whole-body SHA-256,
+// so payload bytes never need to be hashed (or held) up front. payload is
+// read exactly once, sequentially, as the chunked body is drained.
+func newStreamingPutObjectReq(config ServerConfig, bucketName, objectName string, payload io.Reader, decodedLength int64) (*http.Request, error) {
+	targetURL, err := makeTargetURL(config.Endpoint, bucketName, objectName, config.Region, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamingRequest(config, "PUT", targetURL, payload, decodedLength)
+}
+
+// newUploadPartStreamingReq - Create an UploadPart request for the given
+// part of uploadID, signed with STREAMING-AWS4-HMAC-SHA256-PAYLOAD so a
+// large part never needs to be hashed or held in memory up front.
+func newUploadPartStreamingReq(config ServerConfig, bucketName, objectName, uploadID string, partNumber int, payload io.Reader, decodedLength int64) (*http.Request, error) {
+	urlValues := make(url.Values)
+	urlValues.Set("partNumber", strconv.Itoa(partNumber))
+	urlValues.Set("uploadId", uploadID)
+	targetURL, err := makeTargetURL(config.Endpoint, bucketName, objectName, config.Region, urlValues)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamingRequest(config, "PUT", targetURL, payload, decodedLength)
+}
+
+// newStreamingRequest builds and signs a STREAMING-AWS4-HMAC-SHA256-PAYLOAD
+// request for the given method and target URL, shared by
+// newStreamingPutObjectReq and newUploadPartStreamingReq.
+func newStreamingRequest(config ServerConfig, method string, targetURL *url.URL, payload io.Reader, decodedLength int64) (*http.Request, error) {
+	streamingReq := &http.Request{
+		Header: http.Header{},
+		Method: method,
+		URL:    targetURL,
+	}
+	streamingReq.Header.Set("X-Amz-Content-Sha256", "STREAMING-AWS4-HMAC-SHA256-PAYLOAD")
+	streamingReq.Header.Set("Content-Encoding", "aws-chunked")
+	streamingReq.Header.Set("X-Amz-Decoded-Content-Length", strconv.FormatInt(decodedLength, 10))
+	streamingReq.Header.Set("User-Agent", appUserAgent)
+	streamingReq.ContentLength = streamingChunkedLength(decodedLength)
+
+	signedReq := config.Sign(streamingReq)
+	seedSignature, err := parseSigV4Signature(signedReq.Header.Get("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+	xAmzDate := signedReq.Header.Get("X-Amz-Date")
+	if len(xAmzDate) < 8 {
+		return nil, fmt.Errorf("signed streaming request missing X-Amz-Date header")
+	}
+	date := xAmzDate[:8]
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, config.Region)
+	signingKey := deriveStreamingSigningKey(config.Secret, date, config.Region, "s3")
+
+	// noRetryReader (not ioutil.NopCloser) tells execRequest to send this
+	// chunked, chunk-signature-chained body directly instead of buffering
+	// it whole into memory - which would defeat the point of streaming a
+	// >100MiB payload - and not to retry, since the signature chain can't
+	// be replayed from a resend.
+	signedReq.Body = noRetryReader{newStreamingChunkedReader(payload, signingKey, xAmzDate, scope, seedSignature)}
+	return signedReq, nil
+}
+
+// mainPutObjectStreaming - Entry point for a streaming-signed PutObject of
+// a >100MiB object: the payload is generated and hashed on the fly via
+// deterministicReader/sha256.New and sent to execRequest wrapped in
+// noRetryReader, so it is never buffered whole; the uploaded object is
+// verified by re-downloading it and comparing its SHA-256 against the hash
+// taken of the payload as it was sent.
+func mainPutObjectStreaming(config ServerConfig, curTest int) bool {
+	message := fmt.Sprintf("[%02d/%d] PutObject (Streaming Signature):", curTest, globalTotalNumTest)
+	scanBar(message)
+	bucketName := s3verifyBuckets[0].Name
+	objectName := "s3verify-put-object-streaming"
+
+	const size = 101 * 1024 * 1024 // >100MiB.
+	const seed = 42
+
+	hasher := sha256.New()
+	source := io.TeeReader(newDeterministicReader(seed, size), hasher)
+
+	req, err := newStreamingPutObjectReq(config, bucketName, objectName, source, size)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	scanBar(message)
+	res, err := execRequest(req, config.Client, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	closeResponse(res)
+	if res.StatusCode != http.StatusOK {
+		printMessage(message, fmt.Errorf("Unexpected Status Received: wanted %v, got %v", http.StatusOK, res.StatusCode))
+		return false
+	}
+	wantSHA256 := hex.EncodeToString(hasher.Sum(nil))
+	scanBar(message)
+
+	getReq, err := newGetObjectReq(config, bucketName, objectName)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	getRes, err := config.execRequest("GET", getReq)
+	if err != nil {
+		printMessage(message, err)
+		return false
+	}
+	defer closeResponse(getRes)
+	gotHasher := sha256.New()
+	if _, err := io.Copy(gotHasher, getRes.Body); err != nil {
+		printMessage(message, err)
+		return false
+	}
+	if gotSHA256 := hex.EncodeToString(gotHasher.Sum(nil)); gotSHA256 != wantSHA256 {
+		printMessage(message, fmt.Errorf("Unexpected object SHA-256: wanted %v, got %v", wantSHA256, gotSHA256))
+		return false
+	}
+	scanBar(message)
+
+	printMessage(message, nil)
+	return true
+}
@@ -0,0 +1,115 @@
+/*
+ * Minio S3Verify Library for Amazon S3 Compatible Cloud Storage (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+)
+
+// tagInference maps a substring of a Test function's name to the tag it
+// implies. Used to classify existing APItest entries that do not set
+// Tags/API explicitly.
+var tagInference = []struct {
+	substr string
+	tag    string
+}{
+	{"Multipart", "multipart"},
+	{"Presigned", "presigned"},
+	{"Copy", "copy"},
+	{"Range", "range"},
+	{"IfMatch", "conditional"},
+	{"IfNoneMatch", "conditional"},
+	{"IfModifiedSince", "conditional"},
+	{"IfUnModifiedSince", "conditional"},
+}
+
+// tagsForTest returns the effective API name and tag set for a test,
+// preferring explicit APItest.API/Tags and falling back to inference from
+// the Test function's name so --only/--skip/--suite work against the
+// existing test registry without having to annotate every entry by hand.
+func tagsForTest(test APItest, funcName string) (api string, tags []string) {
+	api = test.API
+	if api == "" {
+		api = strings.ToLower(funcName)
+	}
+	if len(test.Tags) > 0 {
+		return api, test.Tags
+	}
+	for _, m := range tagInference {
+		if strings.Contains(funcName, m.substr) {
+			tags = append(tags, m.tag)
+		}
+	}
+	return api, tags
+}
+
+// splitFilterList parses a comma separated --only/--skip/--suite value into
+// a lower-cased lookup set.
+func splitFilterList(value string) map[string]bool {
+	set := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part != "" {
+			set[part] = true
+		}
+	}
+	return set
+}
+
+// testFilter resolves --only/--skip/--suite into a predicate over a test's
+// API name and tags.
+type testFilter struct {
+	only map[string]bool
+	skip map[string]bool
+}
+
+// newTestFilter builds a testFilter from the raw flag values. --suite is a
+// convenience alias for --only.
+func newTestFilter(only, skip, suite string) testFilter {
+	onlySet := splitFilterList(only)
+	for k := range splitFilterList(suite) {
+		onlySet[k] = true
+	}
+	return testFilter{only: onlySet, skip: splitFilterList(skip)}
+}
+
+// allows reports whether test should run given its API name/tags and
+// Critical status. Critical tests are always allowed through: they are
+// prerequisites for later tests in the pipeline and skipping one would
+// either abort the whole run or silently invalidate everything after it, so
+// --only/--skip cannot exclude them.
+func (f testFilter) allows(test APItest, api string, tags []string) bool {
+	if test.Critical {
+		return true
+	}
+	if len(f.skip) > 0 && (f.skip[api] || anyTagMatches(f.skip, tags)) {
+		return false
+	}
+	if len(f.only) > 0 && !(f.only[api] || anyTagMatches(f.only, tags)) {
+		return false
+	}
+	return true
+}
+
+func anyTagMatches(set map[string]bool, tags []string) bool {
+	for _, tag := range tags {
+		if set[tag] {
+			return true
+		}
+	}
+	return false
+}
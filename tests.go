@@ -77,6 +77,21 @@ var preparedTests = []APItest{
 		Extended: false, // ListObjects is not an extended API.
 		Critical: false, // This test does not affect future tests.
 	},
+	APItest{
+		Test:     mainListObjectsV2Pagination,
+		Extended: true,  // ListObjects V2 pagination is an extended API surface.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainListObjectsV2StartAfter,
+		Extended: true,  // ListObjects V2 start-after is an extended API surface.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainListObjectsV2EncodingType,
+		Extended: true,  // ListObjects V2 encoding-type is an extended API surface.
+		Critical: false, // This test does not affect future tests.
+	},
 
 	// Tests for Multipart API.
 	APItest{
@@ -110,6 +125,30 @@ var preparedTests = []APItest{
 		Critical: false, // Abort Multipart test can fail without affecting other tests.
 	},
 
+	// mainCompleteMultipartUploadPrepared above does not exist; register the
+	// real CompleteMultipartUpload entry point directly so
+	// multipartObjects[0].ETag is actually populated for later tests.
+	APItest{
+		Test:     mainCompleteMultipartUpload,
+		Extended: false, // Complete Multipart test must be run even without extended flag being set.
+		Critical: true,  // Later tests rely on multipartObjects[0] being completed.
+	},
+
+	// Test that If-Match/If-None-Match are evaluated correctly against a
+	// completed multipart object's composite ETag.
+	APItest{
+		Test:     mainHeadObjectIfMatchMultipart,
+		Extended: true,  // Conditional HEAD is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test that ListMultipartUploads actually forgets an upload after it is aborted.
+	APItest{
+		Test:     mainAbortMultipartUploadCleanup,
+		Extended: true,  // ListMultipartUploads is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
 	// Tests for HeadBucket API.
 	APItest{
 		Test:     mainHeadBucketPrepared,
@@ -143,6 +182,11 @@ var preparedTests = []APItest{
 		Extended: true,  // HeadObject with if-none-match header is an extended API.
 		Critical: false, // This test does not affect future tests.
 	},
+	APItest{
+		Test:     mainHeadObjectRangePrepared,
+		Extended: true,  // HeadObject with a range header is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
 
 	// Tests for CopyObject API.
 	APItest{
@@ -208,6 +252,36 @@ var preparedTests = []APItest{
 		Extended: true,  // GetObject with range header is an extended API.
 		Critical: false, // This test does not affect future tests.
 	},
+	APItest{
+		Test:     mainGetObjectRangeSuffix,
+		Extended: true,  // GetObject with a suffix range header is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainGetObjectRangeOpenEnded,
+		Extended: true,  // GetObject with an open-ended range header is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainGetObjectRangeSingleByte,
+		Extended: true,  // GetObject with a single-byte range header is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainGetObjectRangeInvalid,
+		Extended: true,  // GetObject with an out-of-bounds range header is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainGetObjectMultiRange,
+		Extended: true,  // GetObject with multiple byte ranges is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainGetObjectMultiRangeUnsatisfiable,
+		Extended: true,  // GetObject with an unsatisfiable range header is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
 
 	// Test for RemoveObject API.
 	APItest{
@@ -227,6 +301,11 @@ var preparedTests = []APItest{
 		Extended: false, // RemoveBucket is not an extended API.
 		Critical: false, // This test does not affect future tests.
 	},
+	APItest{
+		Test:     mainRemoveBucketNotEmpty,
+		Extended: false, // RemoveBucket is not an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
 }
 
 // unpreparedTests - holds all tests that must be run differently based on usage of the --prepared flag.
@@ -273,6 +352,16 @@ var unpreparedTests = []APItest{
 		Extended: false, // ListObjects is not an extended API.
 		Critical: false, // This test does not affect future tests.
 	},
+	APItest{
+		Test:     mainListObjectsV2Pagination,
+		Extended: true,  // ListObjects V2 pagination is an extended API surface.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainListObjectsV2StartAfter,
+		Extended: true,  // ListObjects V2 start-after is an extended API surface.
+		Critical: false, // This test does not affect future tests.
+	},
 
 	// Tests for Multipart API.
 	APItest{
@@ -339,6 +428,11 @@ var unpreparedTests = []APItest{
 		Extended: true,  // HeadObject with if-none-match header is an extended API.
 		Critical: false, // This test does not affect future tests.
 	},
+	APItest{
+		Test:     mainHeadObjectRangeUnPrepared,
+		Extended: true,  // HeadObject with a range header is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
 
 	// Tests for CopyObject API.
 	APItest{
@@ -404,6 +498,366 @@ var unpreparedTests = []APItest{
 		Critical: false, // This test does not affect future tests.
 	},
 
+	// Test for the multi-source UploadPartCopy compose flow.
+	APItest{
+		Test:     mainComposeObject,
+		Extended: true,  // ComposeObject is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for UploadPartCopy failure cases (InvalidRange/EntityTooSmall).
+	APItest{
+		Test:     mainComposeObjectInvalid,
+		Extended: true,  // ComposeObject is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for UploadPartCopy If-Match and ListParts-after-Abort.
+	APItest{
+		Test:     mainComposeObjectAbort,
+		Extended: true,  // ComposeObject is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for UploadPartCopy range-header math at >5 GiB part scale.
+	APItest{
+		Test:     mainComposeObjectLargeRanges,
+		Extended: true,  // ComposeObject is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for UploadPartCopy If-None-Match.
+	APItest{
+		Test:     mainComposeObjectIfNoneMatch,
+		Extended: true,  // ComposeObject is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for an adaptive, concurrent multipart PUT of real part data.
+	APItest{
+		Test:     mainMultipartPutObject,
+		Extended: true,  // Multipart PUT is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for Complete-after-Abort and out-of-order part number failures.
+	APItest{
+		Test:     mainMultipartPutObjectInvalid,
+		Extended: true,  // Multipart PUT is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for CompleteMultipartUpload failure cases (empty Parts/wrong ETag).
+	APItest{
+		Test:     mainCompleteMultipartUploadInvalid,
+		Extended: true,  // Multipart PUT is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test PutObject signed with STREAMING-AWS4-HMAC-SHA256-PAYLOAD for a
+	// large (>100MiB) object.
+	APItest{
+		Test:     mainPutObjectStreaming,
+		Extended: true,  // Exercises a large streaming-signed upload.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Tests for presigned GetObject/PutObject and POST policy uploads.
+	APItest{
+		Test:     mainPresignedGetObject,
+		Extended: true,  // Presigned GetObject is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainPresignedGetObjectExpiryWindows,
+		Extended: true,  // Presigned GetObject is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainPresignedGetObjectExpired,
+		Extended: true,  // Presigned GetObject is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainPresignedPutObject,
+		Extended: true,  // Presigned PutObject is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainPresignedPutObjectContentType,
+		Extended: true,  // Presigned PutObject is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainPresignedPostPolicy,
+		Extended: true,  // POST policy upload is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for GetBucketLocation API.
+	APItest{
+		Test:     mainGetBucketLocation,
+		Extended: false, // GetBucketLocation is not an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for GetBucketLocation against the wrong region endpoint.
+	APItest{
+		Test:     mainGetBucketLocationWrongRegion,
+		Extended: true,  // Requires a second signing region; treated as extended.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for automatic region-mismatch retry in GetBucketLocation.
+	APItest{
+		Test:     mainGetBucketLocationAutoRetry,
+		Extended: true,  // Requires a second signing region; treated as extended.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for PutBucketNotificationConfiguration/GetBucketNotificationConfiguration API.
+	APItest{
+		Test:     mainPutBucketNotification,
+		Extended: true,  // BucketNotification is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for the GetBucketNotificationConfiguration API on its own.
+	APItest{
+		Test:     mainGetBucketNotification,
+		Extended: true,  // BucketNotification is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for the Minio ListenBucketNotification streaming API.
+	APItest{
+		Test:     mainListenBucketNotification,
+		Extended: true,  // ListenBucketNotification is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainListenBucketNotificationRemove,
+		Extended: true,  // ListenBucketNotification is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for PutBucketNotification rejection of invalid ARNs/event names.
+	APItest{
+		Test:     mainPutBucketNotificationInvalid,
+		Extended: true,  // BucketNotification is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for PutBucketPolicy API.
+	APItest{
+		Test:     mainPutBucketPolicy,
+		Extended: true,  // BucketPolicy is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test that an unsigned GET is allowed/denied per a PutBucketPolicy
+	// Resource match.
+	APItest{
+		Test:     mainPutBucketPolicyAnonymousGet,
+		Extended: true,  // BucketPolicy is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for the GetBucketPolicy API on its own.
+	APItest{
+		Test:     mainGetBucketPolicy,
+		Extended: true,  // BucketPolicy is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for the DeleteBucketPolicy API on its own.
+	APItest{
+		Test:     mainDeleteBucketPolicy,
+		Extended: true,  // BucketPolicy is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test that deleting a PutBucketPolicy revokes the anonymous access it
+	// had granted.
+	APItest{
+		Test:     mainDeleteBucketPolicyAnonymousGet,
+		Extended: true,  // BucketPolicy is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for PutBucketPolicy API with invalid policy documents.
+	APItest{
+		Test:     mainPutBucketPolicyInvalid,
+		Extended: true,  // BucketPolicy is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for Put/GetObjectACL API.
+	APItest{
+		Test:     mainObjectACL,
+		Extended: true,  // ObjectACL is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for PutObjectTagging API.
+	APItest{
+		Test:     mainPutObjectTagging,
+		Extended: true,  // ObjectTagging is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for PutBucketLifecycleConfiguration API.
+	APItest{
+		Test:     mainPutBucketLifecycle,
+		Extended: true,  // BucketLifecycle is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainPutBucketLifecycleAdvanced,
+		Extended: true,  // BucketLifecycle is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainPutBucketLifecycleInvalid,
+		Extended: true,  // BucketLifecycle is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainPutBucketLifecycleMultiRule,
+		Extended: true,  // BucketLifecycle is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for SelectObjectContent API.
+	APItest{
+		Test:     mainSelectObjectContent,
+		Extended: true,  // SelectObjectContent is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for SelectObjectContent WHERE-clause and GZIP-input handling.
+	APItest{
+		Test:     mainSelectObjectContentWhereGzip,
+		Extended: true,  // SelectObjectContent is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for SelectObjectContent column projection, LIMIT, and a missing
+	// ExpressionType rejection.
+	APItest{
+		Test:     mainSelectObjectContentLimitProjection,
+		Extended: true,  // SelectObjectContent is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for PutObject/GetObject with SSE-C.
+	APItest{
+		Test:     mainPutObjectSSEC,
+		Extended: true,  // Encryption is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for GetObject against a SSE-C object: missing key, wrong key,
+	// and the right key, plus the ETag-is-not-plaintext-MD5 requirement.
+	APItest{
+		Test:     mainGetObjectSSEC,
+		Extended: true,  // Encryption is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for PutObject/GetObject with SSE-S3.
+	APItest{
+		Test:     mainPutObjectSSES3,
+		Extended: true,  // Encryption is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for SSE-C headers rejected on a non-TLS endpoint.
+	APItest{
+		Test:     mainPutObjectSSECInsecure,
+		Extended: true,  // Encryption is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for CopyObject with SSE-C source/destination keys.
+	APItest{
+		Test:     mainCopyObjectSSEC,
+		Extended: true,  // Encryption is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for CopyObject with x-amz-metadata-directive REPLACE and COPY.
+	APItest{
+		Test:     mainCopyObjectMetadataDirective,
+		Extended: true,  // CopyObject with metadata-directive is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for PutObject with SSE-KMS.
+	APItest{
+		Test:     mainPutObjectSSEKMS,
+		Extended: true,  // Encryption is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for UploadPart with SSE-C.
+	APItest{
+		Test:     mainUploadPartSSEC,
+		Extended: true,  // Encryption is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for HeadObject with SSE-C, including the missing-key and
+	// mismatched-key-MD5 negative cases.
+	APItest{
+		Test:     mainHeadObjectSSEC,
+		Extended: true,  // Encryption is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for CompleteMultipartUpload of a SSE-C encrypted multipart upload.
+	APItest{
+		Test:     mainCompleteMultipartUploadSSEC,
+		Extended: true,  // Encryption is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test that If-Match/If-None-Match take precedence over
+	// If-Unmodified-Since/If-Modified-Since when both are present.
+	APItest{
+		Test:     mainHeadObjectConditionalPrecedence,
+		Extended: true,  // Conditional HEAD matrix is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
+	// Test for Object Lock, Retention, and Legal Hold.
+	APItest{
+		Test:     mainObjectLockGovernance,
+		Extended: true,  // Object Lock is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainObjectLockCompliance,
+		Extended: true,  // Object Lock is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	APItest{
+		Test:     mainObjectLegalHold,
+		Extended: true,  // Object Lock is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+	// mainHeadObjectRetention depends on the bucket/object mainPutObjectRetention creates.
+	APItest{
+		Test:     mainPutObjectRetention,
+		Extended: true,  // Object Lock is an extended API.
+		Critical: false, // A failure does not stop the run; mainHeadObjectRetention uses its object.
+	},
+	APItest{
+		Test:     mainHeadObjectRetention,
+		Extended: true,  // Object Lock is an extended API.
+		Critical: false, // This test does not affect future tests.
+	},
+
 	// Test for RemoveObject API.
 	APItest{
 		Test:     mainRemoveObjectExistsUnPrepared,
@@ -411,6 +865,13 @@ var unpreparedTests = []APItest{
 		Critical: true,  // Remove Object test must pass for future tests.
 	},
 
+	// Test for BulkDelete (RemoveObjects) API.
+	APItest{
+		Test:     mainRemoveObjects,
+		Extended: true,  // BulkDelete is an extended API.
+		Critical: false, // This test operates on its own throwaway objects.
+	},
+
 	// Tests for RemoveBucket API.
 	APItest{
 		Test:     mainRemoveBucketExistsUnPrepared,
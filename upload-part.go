@@ -30,7 +30,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/minio/s3verify/signv4"
 )
 
 // Store parts to be listed.
@@ -78,7 +77,7 @@ func newUploadPartReq(config ServerConfig, bucketName, objectName, uploadID stri
 	uploadPartReq.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum))
 	uploadPartReq.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum))
 
-	uploadPartReq = signv4.SignV4(*uploadPartReq, config.Access, config.Secret, config.Region)
+	uploadPartReq = config.Sign(uploadPartReq)
 	return uploadPartReq, nil
 }
 
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"crypto/md5"
 	"crypto/sha256"
+	"encoding/hex"
 	"encoding/xml"
 	"fmt"
 	"hash"
@@ -28,7 +29,9 @@ import (
 	"math/rand"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/minio/mc/pkg/console"
@@ -48,8 +51,50 @@ var successStatus = []int{
 	http.StatusPartialContent,
 }
 
+// lastTestError captures the error passed to the most recent printMessage
+// call, so runAndRecord can attach the failure reason to the test's
+// report.Record without changing every mainXxx function's signature.
+// Best-effort: under --parallel > 1, concurrent tests race on this value
+// and the recorded error can be misattributed; it is exact for the default
+// serial run.
+var (
+	lastTestErrorMu sync.Mutex
+	lastTestError   error
+	// lastTestSkipped records whether the most recent printMessage/
+	// skipMessage call was a skip (provider capability gate) rather than a
+	// pass or fail. Same best-effort serial-run caveat as lastTestError.
+	lastTestSkipped bool
+	// lastTestRequestID/lastTestHostID capture the x-amz-request-id/
+	// x-amz-id-2 headers off the most recent response execRequest saw, so a
+	// failure against real S3 can be filed with Amazon support by ID. Same
+	// best-effort serial-run caveat as lastTestError.
+	lastTestRequestID string
+	lastTestHostID    string
+)
+
+// recordRequestID saves res's x-amz-request-id/x-amz-id-2 headers, if
+// present, into lastTestRequestID/lastTestHostID.
+func recordRequestID(res *http.Response) {
+	if res == nil {
+		return
+	}
+	requestID := res.Header.Get("x-amz-request-id")
+	hostID := res.Header.Get("x-amz-id-2")
+	if requestID == "" && hostID == "" {
+		return
+	}
+	lastTestErrorMu.Lock()
+	lastTestRequestID = requestID
+	lastTestHostID = hostID
+	lastTestErrorMu.Unlock()
+}
+
 // printMessage - Print test pass/fail messages with errors.
 func printMessage(message string, err error) {
+	lastTestErrorMu.Lock()
+	lastTestError = err
+	lastTestSkipped = false
+	lastTestErrorMu.Unlock()
 	// Erase the old progress line.
 	console.Eraseline()
 	if err != nil {
@@ -61,6 +106,23 @@ func printMessage(message string, err error) {
 	}
 }
 
+// skipMessage reports a test as skipped rather than passed or failed, for a
+// provider that declares, via providerQuirks.Supports, that it doesn't
+// implement the feature under test. Always returns true so the caller can
+// write "return skipMessage(...)" in place of its usual printMessage/return
+// pair; runAndRecordStyle consults lastTestSkipped to record report.StatusSkip
+// instead of report.StatusPass.
+func skipMessage(message, reason string) bool {
+	lastTestErrorMu.Lock()
+	lastTestError = nil
+	lastTestSkipped = true
+	lastTestErrorMu.Unlock()
+	console.Eraseline()
+	message += strings.Repeat(" ", messageWidth-len([]rune(message))) + "[SKIP] " + reason
+	console.Println(message)
+	return true
+}
+
 // verifyHostReachable - Execute a simple get request against the provided endpoint to make sure its reachable.
 func verifyHostReachable(endpoint, region string) error {
 	targetURL, err := makeTargetURL(endpoint, "", "", region, nil)
@@ -87,18 +149,63 @@ func xmlDecoder(body io.Reader, v interface{}) error {
 	return d.Decode(v)
 }
 
+// readSeekCloser adapts an io.ReadSeeker (e.g. a *bytes.Reader backing an
+// in-memory fixture) into an io.ReadCloser that still satisfies
+// io.ReadSeeker, so callers that hand execRequest a seekable body (and want
+// its seek-and-retry fast path instead of the buffer-the-whole-body
+// fallback) aren't forced through ioutil.NopCloser, whose wrapper type
+// drops Seek.
+type readSeekCloser struct {
+	io.ReadSeeker
+}
+
+// Close is a no-op: readSeekCloser only ever wraps in-memory readers that
+// own no underlying resource to release.
+func (readSeekCloser) Close() error { return nil }
+
+// noRetryReader marks a request body that can only be read once — e.g. a
+// chunk-signed STREAMING-AWS4-HMAC-SHA256-PAYLOAD body, whose per-chunk
+// signature chain can't be recomputed from a byte buffer the way a plain
+// retry-by-resend could. execRequest recognizes this type and sends it
+// directly, without buffering it into memory first, and does not retry if
+// the send fails.
+type noRetryReader struct {
+	io.Reader
+}
+
+// Close is a no-op: noRetryReader wraps readers (e.g. streamingChunkedReader)
+// that own no underlying resource to release.
+func (noRetryReader) Close() error { return nil }
+
 // execRequest - Executes an HTTP request creating an HTTP response and implements retry logic for predefined retryable errors.
 func execRequest(req *http.Request, client *http.Client, bucketName, objectName string) (resp *http.Response, err error) {
+	// Capture x-amz-request-id/x-amz-id-2 off whatever response comes back,
+	// successful or not, so a report.Record can cite them for support cases.
+	defer func() { recordRequestID(resp) }()
 	var isRetryable bool         // Indicates if request can be retried.
+	var singleUse bool           // Indicates req.Body can only be sent once, unbuffered.
 	var bodyReader io.ReadSeeker // io.Seeking for seeking.
 	if req.Body != nil {
-		// FIXME: remove this and reduce ioutil.NopCloser usage elsewhere.
-		buf, err := ioutil.ReadAll(req.Body)
-		if err != nil {
-			return nil, err
+		if _, ok := req.Body.(noRetryReader); ok {
+			// A single-use streaming body: send it as-is so it never gets
+			// buffered whole into memory, and don't retry - there is no
+			// way to replay it.
+			singleUse = true
+		} else if seeker, ok := req.Body.(io.ReadSeeker); ok {
+			// req.Body is already seekable (e.g. an *os.File backing a
+			// multi-GB upload test): retry by seeking it back to the
+			// start instead of buffering the whole thing into memory.
+			isRetryable = true
+			bodyReader = seeker
+		} else {
+			// FIXME: remove this and reduce ioutil.NopCloser usage elsewhere.
+			buf, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			isRetryable = true
+			bodyReader = bytes.NewReader(buf)
 		}
-		isRetryable = true
-		bodyReader = bytes.NewReader(buf)
 	}
 	// Do not need the index.
 	for _ = range newRetryTimer(MaxRetry, time.Second, time.Second*30, MaxJitter, globalRandom) {
@@ -115,7 +222,7 @@ func execRequest(req *http.Request, client *http.Client, bucketName, objectName
 		resp, err = client.Do(req)
 		if err != nil {
 			// For supported network errors verify.
-			if isNetErrorRetryable(err) {
+			if !singleUse && isNetErrorRetryable(err) {
 				continue // Retry.
 			}
 			// For other errors there is no need to retry.
@@ -136,6 +243,12 @@ func execRequest(req *http.Request, client *http.Client, bucketName, objectName
 		errBodySeeker := bytes.NewReader(errBodyBytes)
 		resp.Body = ioutil.NopCloser(errBodySeeker)
 
+		if singleUse {
+			// The body has already been fully drained once and can't be
+			// resent: whatever the server returned is final.
+			break
+		}
+
 		// For errors verify if its retryable otherwise fail quickly.
 		errResponse := ToErrorResponse(httpRespToErrorResponse(resp, bucketName, objectName))
 
@@ -207,7 +320,54 @@ func isAmazonEndpoint(endpointURL *url.URL) bool {
 	return false
 }
 
-// Generate a new URL from the user provided endpoint.
+// ipAddressRegex matches a dotted-quad IPv4 literal host, which can never be
+// addressed in virtual-hosted style.
+var ipAddressRegex = regexp.MustCompile(`^(\d{1,3}\.){3}\d{1,3}$`)
+
+// isDNSCompliantBucketName checks whether bucketName can be used as a DNS
+// label, i.e. as the leftmost component of a virtual-hosted-style host name.
+// This mirrors the bucket naming rules S3 enforces: 3-63 lowercase
+// alphanumeric characters, hyphens, and periods, starting and ending with a
+// letter or digit, with no adjacent periods and no "-."/".-" sequences.
+func isDNSCompliantBucketName(bucketName string) bool {
+	if len(bucketName) < 3 || len(bucketName) > 63 {
+		return false
+	}
+	if ipAddressRegex.MatchString(bucketName) {
+		return false
+	}
+	prevIsPeriod := false
+	for i, c := range bucketName {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			prevIsPeriod = false
+		case c == '.':
+			if prevIsPeriod {
+				return false
+			}
+			prevIsPeriod = true
+		case c == '-':
+			prevIsPeriod = false
+		default:
+			return false
+		}
+		if i == 0 || i == len(bucketName)-1 {
+			if c == '.' || c == '-' {
+				return false
+			}
+		}
+	}
+	return !prevIsPeriod
+}
+
+// Generate a new URL from the user provided endpoint. The URL style
+// (path-style "/{bucket}/{key}" vs. virtual-hosted-style
+// "{bucket}.{endpoint}/{key}") is controlled by globalAddressingStyle
+// ("path" by default); "auto" is resolved to one style per call by
+// runAndRecord, which re-invokes every test once per style and never
+// observes "auto" here. Virtual-hosted style additionally requires the
+// bucket name to be DNS-compliant and the endpoint host to not already be an
+// IP literal; both fall back to path-style, matching the AWS SDKs.
 func makeTargetURL(endpoint, bucketName, objectName, region string, queryValues url.Values) (*url.URL, error) {
 	targetURL, err := url.Parse(endpoint)
 	if err != nil {
@@ -216,9 +376,18 @@ func makeTargetURL(endpoint, bucketName, objectName, region string, queryValues
 	if isAmazonEndpoint(targetURL) { // Change host to reflect the region.
 		targetURL.Host = getS3Endpoint(region)
 	}
-	targetURL.Path = "/"
-	if bucketName != "" {
-		targetURL.Path = "/" + bucketName + "/" + objectName // Use path style requests only.
+	useVirtualStyle := globalAddressingStyle == "virtual" &&
+		bucketName != "" &&
+		isDNSCompliantBucketName(bucketName) &&
+		!ipAddressRegex.MatchString(targetURL.Hostname())
+	if useVirtualStyle {
+		targetURL.Host = bucketName + "." + targetURL.Host
+		targetURL.Path = "/" + objectName
+	} else {
+		targetURL.Path = "/"
+		if bucketName != "" {
+			targetURL.Path = "/" + bucketName + "/" + objectName
+		}
 	}
 	if len(queryValues) > 0 { // If there are query values include them.
 		targetURL.RawQuery = queryValues.Encode()
@@ -270,3 +439,33 @@ func computeHash(reader io.ReadSeeker) (md5Sum, sha256Sum []byte, contentLength
 
 	return md5Sum, sha256Sum, contentLength, nil
 }
+
+// unsignedPayloadThreshold is the body size above which
+// computeHashOrUnsigned skips hashing and falls back to AWS's
+// UNSIGNED-PAYLOAD mode, so multi-GB upload tests don't pay for a full
+// MD5+SHA256 pass (and the accompanying double read) in memory.
+const unsignedPayloadThreshold = 64 * 1024 * 1024 // 64MiB
+
+// computeHashOrUnsigned behaves like computeHash for bodies at or below
+// unsignedPayloadThreshold. Above it, md5Sum/sha256Sum are left nil and
+// payloadSha256Header is "UNSIGNED-PAYLOAD" instead of a hex-encoded digest,
+// so callers can skip payload hashing entirely for large uploads. The caller
+// is responsible for using an unsigned-payload-capable Signer when that mode
+// is selected.
+func computeHashOrUnsigned(reader io.ReadSeeker) (md5Sum, sha256Sum []byte, contentLength int64, payloadSha256Header string, err error) {
+	contentLength, err = reader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, nil, 0, "", err
+	}
+	if _, err = reader.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, 0, "", err
+	}
+	if contentLength > unsignedPayloadThreshold {
+		return nil, nil, contentLength, "UNSIGNED-PAYLOAD", nil
+	}
+	md5Sum, sha256Sum, contentLength, err = computeHash(reader)
+	if err != nil {
+		return nil, nil, 0, "", err
+	}
+	return md5Sum, sha256Sum, contentLength, hex.EncodeToString(sha256Sum), nil
+}